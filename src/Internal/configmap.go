@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// GetConfigMapData returns a key of a ConfigMap, empty when the ConfigMap or the key does not exist
+func GetConfigMapData(namespace, name, key string) (string, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return "", err
+	}
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return cm.Data[key], nil
+}
+
+// UpdateConfigMapData rewrites a key of a ConfigMap with mutate, creating the
+// ConfigMap when needed and retrying when a concurrent writer got there first
+func UpdateConfigMapData(namespace, name, key string, mutate func(old string) (string, error)) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	configMaps := clientset.CoreV1().ConfigMaps(namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			value, err := mutate("")
+			if err != nil {
+				return err
+			}
+			_, err = configMaps.Create(context.TODO(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "spacio"},
+				},
+				Data: map[string]string{key: value},
+			}, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		value, err := mutate(cm.Data[key])
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = value
+		_, err = configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// ListEventsForObject returns the events of an object, newest first
+func ListEventsForObject(namespace, kind, name string) ([]corev1.Event, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := fields.Set{
+		"involvedObject.kind": kind,
+		"involvedObject.name": name,
+	}.AsSelector().String()
+
+	events, err := clientset.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing events for %s %s/%s: %v", kind, namespace, name, err)
+	}
+
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return EventTime(items[i]).After(EventTime(items[j]))
+	})
+	return items, nil
+}
+
+// EventTime returns the most meaningful timestamp of an event
+func EventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.FirstTimestamp.Time
+	}
+}
+
+// RecordEvent records a Kubernetes Event on an object, reported by spacio
+func RecordEvent(ref corev1.ObjectReference, eventType, reason, message string) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: ref.Name + ".",
+			Namespace:    ref.Namespace,
+		},
+		InvolvedObject: ref,
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         corev1.EventSource{Component: "spacio"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := clientset.CoreV1().Events(ref.Namespace).Create(context.TODO(), event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error recording event %s on %s %s/%s: %v", reason, ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return nil
+}
+
+// PVCReference returns the object reference of a PVC, for events
+func PVCReference(pvc corev1.PersistentVolumeClaim) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		Kind:            "PersistentVolumeClaim",
+		APIVersion:      "v1",
+		Namespace:       pvc.Namespace,
+		Name:            pvc.Name,
+		UID:             pvc.UID,
+		ResourceVersion: pvc.ResourceVersion,
+	}
+}
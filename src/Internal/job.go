@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateJob creates a Job
+func CreateJob(job *batchv1.Job) (*batchv1.Job, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.BatchV1().Jobs(job.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+}
+
+// GetJob returns a single Job by name
+func GetJob(namespace, name string) (*batchv1.Job, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.BatchV1().Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// DeleteJob deletes a Job together with its pods
+func DeleteJob(namespace, name string) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	return clientset.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// JobCondition returns the true condition of the given type, nil if not set
+func JobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == conditionType && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// latestJobPod returns the most recently created pod of a Job
+func latestJobPod(namespace, jobName string) (*corev1.Pod, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods of job %s/%s: %v", namespace, jobName, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("job %s/%s has no pods", namespace, jobName)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.After(pods.Items[j].CreationTimestamp.Time)
+	})
+	return &pods.Items[0], nil
+}
+
+// RunningJobPod returns the name of the running pod of a Job
+func RunningJobPod(namespace, jobName string) (string, error) {
+	pod, err := latestJobPod(namespace, jobName)
+	if err != nil {
+		return "", err
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return "", fmt.Errorf("pod %s/%s is %s", namespace, pod.Name, pod.Status.Phase)
+	}
+	return pod.Name, nil
+}
+
+// JobLogs returns the logs of the most recent pod of a Job
+func JobLogs(namespace, jobName string) (string, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return "", err
+	}
+	pod, err := latestJobPod(namespace, jobName)
+	if err != nil {
+		return "", err
+	}
+	data, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw(context.TODO())
+	if err != nil {
+		return "", fmt.Errorf("error reading logs of pod %s/%s: %v", namespace, pod.Name, err)
+	}
+	return string(data), nil
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -87,6 +89,20 @@ func GetK8sClient() (*kubernetes.Clientset, error) {
 
 	return clientset, nil
 }
+
+// GetDynamicClient returns a dynamic client for APIs without typed clients (e.g. VolumeSnapshots)
+func GetDynamicClient() (dynamic.Interface, error) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get kubeconfig: %v", err)
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create dynamic client: %v", err)
+	}
+	return client, nil
+}
+
 func GetClusterName() string {
 	// Try in-cluster config first
 	config, err := rest.InClusterConfig()
@@ -129,3 +145,12 @@ func ListNamespaces() ([]string, error) {
 	}
 	return namespaces, nil
 }
+
+// GetNamespace returns a single namespace object
+func GetNamespace(name string) (*corev1.Namespace, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CreatePVC creates a PVC
+func CreatePVC(pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+}
+
+// PatchPV applies a JSON merge patch to a PersistentVolume
+func PatchPV(name string, patch map[string]interface{}) (*corev1.PersistentVolume, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().PersistentVolumes().Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+}
+
+// SetPVReclaimPolicy changes the reclaim policy of a PV and sets the given
+// annotations (a nil value removes the annotation)
+func SetPVReclaimPolicy(name string, policy corev1.PersistentVolumeReclaimPolicy, annotations map[string]interface{}) (*corev1.PersistentVolume, error) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"persistentVolumeReclaimPolicy": policy},
+	}
+	if len(annotations) > 0 {
+		patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	}
+	return PatchPV(name, patch)
+}
+
+// ReservePVForClaim points the claimRef of a Released PV at a claim that does
+// not exist yet, so that only a PVC with that namespace and name can bind it
+func ReservePVForClaim(pvName, namespace, claimName string) (*corev1.PersistentVolume, error) {
+	return PatchPV(pvName, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"claimRef": map[string]interface{}{
+				"namespace":       namespace,
+				"name":            claimName,
+				"uid":             nil,
+				"resourceVersion": nil,
+			},
+		},
+	})
+}
+
+// DeletePV deletes a PersistentVolume object; the backend volume is only
+// removed by the provisioner when the reclaim policy says so
+func DeletePV(name string) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// PatchPVCAnnotations sets annotations on a PVC (a nil value removes the annotation)
+func PatchPVCAnnotations(namespace, pvcName string, annotations map[string]interface{}) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = clientset.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), pvcName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
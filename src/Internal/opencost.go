@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// openCostTimeout bounds a single allocation API request
+const openCostTimeout = 30 * time.Second
+
+// OpenCostPV is the part of an OpenCost PV allocation used for pricing
+type OpenCostPV struct {
+	ByteHours float64 `json:"byteHours"`
+	Cost      float64 `json:"cost"`
+}
+
+// openCostAllocation is the part of an OpenCost allocation used for pricing
+type openCostAllocation struct {
+	Name string                `json:"name"`
+	PVs  map[string]OpenCostPV `json:"pvs"`
+}
+
+// openCostResponse is the envelope of the /allocation API. Each element of
+// data is an allocation set keyed by the aggregation name.
+type openCostResponse struct {
+	Code    int                             `json:"code"`
+	Message string                          `json:"message"`
+	Data    []map[string]openCostAllocation `json:"data"`
+}
+
+// FetchOpenCostPVCosts queries an OpenCost-compatible allocation API and
+// returns the cost and byte-hours of each PersistentVolume over the window,
+// keyed by PV name. Shares of a PV mounted by several pods are summed, and
+// unmounted PVs are included through OpenCost's __unmounted__ allocation.
+func FetchOpenCostPVCosts(client *http.Client, baseURL, window string) (map[string]OpenCostPV, error) {
+	if client == nil {
+		client = &http.Client{Timeout: openCostTimeout}
+	}
+
+	query := url.Values{}
+	query.Set("window", window)
+	query.Set("aggregate", "namespace,pod")
+	query.Set("accumulate", "true")
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/allocation?" + query.Encode()
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("querying OpenCost allocation API: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenCost allocation API returned %s", resp.Status)
+	}
+
+	var body openCostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding OpenCost allocation response: %v", err)
+	}
+	if body.Code != 0 && body.Code != http.StatusOK {
+		return nil, fmt.Errorf("OpenCost allocation API error %d: %s", body.Code, body.Message)
+	}
+
+	costs := map[string]OpenCostPV{}
+	for _, set := range body.Data {
+		for _, alloc := range set {
+			for key, pv := range alloc.PVs {
+				name := openCostPVName(key)
+				total := costs[name]
+				total.Cost += pv.Cost
+				total.ByteHours += pv.ByteHours
+				costs[name] = total
+			}
+		}
+	}
+	return costs, nil
+}
+
+// openCostPVName extracts the PV name from an OpenCost PV key, which is
+// serialized as "cluster=<cluster>:name=<pv>" (older releases: "<cluster>/<pv>")
+func openCostPVName(key string) string {
+	if i := strings.LastIndex(key, "name="); i >= 0 {
+		return key[i+len("name="):]
+	}
+	if i := strings.LastIndexAny(key, "/:"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchOpenCostPVCosts(t *testing.T) {
+	const allocations = `{"code":200,"data":[{
+		"db/postgres-0":{"name":"db/postgres-0","pvs":{"cluster=one:name=pv-data":{"byteHours":100,"cost":1.5}}},
+		"db/postgres-1":{"name":"db/postgres-1","pvs":{"cluster=one:name=pv-data":{"byteHours":50,"cost":0.5}}},
+		"__unmounted__/__unmounted__":{"name":"__unmounted__","pvs":{"one/pv-orphan":{"byteHours":10,"cost":0.25}}}
+	}]}`
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    map[string]OpenCostPV
+		wantErr string
+	}{
+		{
+			name:   "shares summed and unmounted included",
+			status: http.StatusOK,
+			body:   allocations,
+			want:   map[string]OpenCostPV{"pv-data": {ByteHours: 150, Cost: 2}, "pv-orphan": {ByteHours: 10, Cost: 0.25}},
+		},
+		{name: "HTTP error", status: http.StatusBadGateway, body: "", wantErr: "502"},
+		{name: "API error", status: http.StatusOK, body: `{"code":400,"message":"bad window"}`, wantErr: "bad window"},
+		{name: "invalid JSON", status: http.StatusOK, body: "{", wantErr: "decoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/allocation" {
+					http.NotFound(w, r)
+					return
+				}
+				query = r.URL.RawQuery
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := FetchOpenCostPVCosts(server.Client(), server.URL+"/", "7d")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FetchOpenCostPVCosts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchOpenCostPVCosts() error = %v", err)
+			}
+			if want := "accumulate=true&aggregate=namespace%2Cpod&window=7d"; query != want {
+				t.Errorf("query = %q, want %q", query, want)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("FetchOpenCostPVCosts() = %v, want %v", got, tt.want)
+			}
+			for name, pv := range tt.want {
+				if got[name] != pv {
+					t.Errorf("%s = %+v, want %+v", name, got[name], pv)
+				}
+			}
+		})
+	}
+}
@@ -5,34 +5,131 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-// FindPodsForPVC returns a list of pod names that mount the given PVC in a namespace
-func FindPodsForPVC(namespace, pvcName string) ([]string, error) {
-	clientset, err := GetK8sClient()
+// Attachment states of a PVC, derived from the phases of the pods referencing it
+const (
+	AttachmentRunning    = "Running"
+	AttachmentPending    = "Pending"
+	AttachmentTerminated = "Terminated"
+	AttachmentUnknown    = "Unknown"
+	AttachmentNone       = "Unattached"
+)
+
+// PodAttachment describes a pod that references a PVC
+type PodAttachment struct {
+	PodName   string
+	UID       types.UID
+	Phase     string // Running, Pending, Terminated or Unknown
+	Container string // first container mounting the volume (empty if none mounts it)
+	MountPath string
+}
+
+// Active reports whether the pod is running and the volume can be inspected via exec
+func (a PodAttachment) Active() bool {
+	return a.Phase == AttachmentRunning
+}
+
+// PodPhase maps a pod to its attachment phase. Succeeded/Failed pods and pods
+// being deleted are Terminated.
+func PodPhase(pod corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return AttachmentTerminated
+	}
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		return AttachmentRunning
+	case corev1.PodPending:
+		return AttachmentPending
+	case corev1.PodSucceeded, corev1.PodFailed:
+		return AttachmentTerminated
+	default:
+		return AttachmentUnknown
+	}
+}
+
+// PodAttachmentsForPVC returns every pod in the list that references the PVC, with its phase
+func PodAttachmentsForPVC(pods []corev1.Pod, pvcName string) []PodAttachment {
+	result := []PodAttachment{}
+	for _, pod := range pods {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim == nil || vol.PersistentVolumeClaim.ClaimName != pvcName {
+				continue
+			}
+			attachment := PodAttachment{PodName: pod.Name, UID: pod.UID, Phase: PodPhase(pod)}
+		containers:
+			for _, container := range pod.Spec.Containers {
+				for _, vm := range container.VolumeMounts {
+					if vm.Name == vol.Name {
+						attachment.Container = container.Name
+						attachment.MountPath = vm.MountPath
+						break containers
+					}
+				}
+			}
+			result = append(result, attachment)
+		}
+	}
+	return result
+}
+
+// AttachmentState summarises pod attachments: Running if any pod runs, otherwise
+// Pending, Terminated or Unattached. Only Running counts as attached.
+func AttachmentState(attachments []PodAttachment) string {
+	state := AttachmentNone
+	for _, a := range attachments {
+		switch a.Phase {
+		case AttachmentRunning:
+			return AttachmentRunning
+		case AttachmentPending:
+			state = AttachmentPending
+		case AttachmentUnknown:
+			if state != AttachmentPending {
+				state = AttachmentUnknown
+			}
+		case AttachmentTerminated:
+			if state == AttachmentNone {
+				state = AttachmentTerminated
+			}
+		}
+	}
+	return state
+}
+
+// FindPodAttachmentsForPVC returns all pods referencing the PVC in a namespace, with their phase
+func FindPodAttachmentsForPVC(namespace, pvcName string) ([]PodAttachment, error) {
+	pods, err := ListPods(namespace)
 	if err != nil {
 		return nil, err
 	}
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	return PodAttachmentsForPVC(pods, pvcName), nil
+}
+
+// FindPodsForPVC returns a list of running pod names that mount the given PVC in a namespace
+func FindPodsForPVC(namespace, pvcName string) ([]string, error) {
+	attachments, err := FindPodAttachmentsForPVC(namespace, pvcName)
 	if err != nil {
 		return nil, err
 	}
 
 	result := []string{}
-	for _, pod := range pods.Items {
-		for _, vol := range pod.Spec.Volumes {
-			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
-				result = append(result, pod.Name)
-			}
+	for _, a := range attachments {
+		if a.Active() {
+			result = append(result, a.PodName)
 		}
 	}
 	return result, nil
 }
 func ListPods(namespace string) ([]corev1.Pod, error) {
-	client, _ := GetK8sClient()
+	client, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
 	podList, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("error listing pods in %s: %v", namespace, err)
@@ -40,32 +137,19 @@ func ListPods(namespace string) ([]corev1.Pod, error) {
 	return podList.Items, nil
 }
 
-// FindPodAndMountPathForPVC returns the first pod and mount path that is using the PVC
+// FindPodAndMountPathForPVC returns the first running pod and mount path that is using the PVC
 func FindPodAndMountPathForPVC(namespace, pvcName string) (string, string, error) {
-	clientset, err := GetK8sClient()
-	if err != nil {
-		return "", "", err
-	}
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	attachments, err := FindPodAttachmentsForPVC(namespace, pvcName)
 	if err != nil {
 		return "", "", err
 	}
 
-	for _, pod := range pods.Items {
-		for _, vol := range pod.Spec.Volumes {
-			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
-				// find the mount path from the first container
-				for _, container := range pod.Spec.Containers {
-					for _, vm := range container.VolumeMounts {
-						if vm.Name == vol.Name {
-							return pod.Name, vm.MountPath, nil
-						}
-					}
-				}
-			}
+	for _, a := range attachments {
+		if a.Active() && a.MountPath != "" {
+			return a.PodName, a.MountPath, nil
 		}
 	}
-	return "", "", fmt.Errorf("no pod found using PVC %s", pvcName)
+	return "", "", fmt.Errorf("no running pod found using PVC %s", pvcName)
 }
 
 // ExecInPod executes a command in a pod container and returns stdout as string
@@ -81,3 +165,77 @@ func ExecInPod(podName, namespace, command string) (string, error) {
 	}
 	return out.String(), nil
 }
+
+// LastPodActivityForPVC returns the most recent start or termination time of any pod
+// (in any phase) that references the PVC. Zero time means no pod references it.
+func LastPodActivityForPVC(pods []corev1.Pod, pvcName string) time.Time {
+	var last time.Time
+	for _, pod := range pods {
+		if !podReferencesPVC(pod, pvcName) {
+			continue
+		}
+		if pod.Status.StartTime != nil && pod.Status.StartTime.Time.After(last) {
+			last = pod.Status.StartTime.Time
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if t := cs.State.Terminated; t != nil && t.FinishedAt.Time.After(last) {
+				last = t.FinishedAt.Time
+			}
+			if r := cs.State.Running; r != nil && pod.Status.Phase == corev1.PodRunning {
+				// a running pod is active right now
+				last = time.Now()
+			}
+		}
+	}
+	return last
+}
+
+func podReferencesPVC(pod corev1.Pod, pvcName string) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
+			return true
+		}
+	}
+	return false
+}
+
+// PodsHoldingPVC returns the pods that still reference the PVC and may have it
+// mounted: every pod except Succeeded/Failed ones that are not being deleted
+func PodsHoldingPVC(namespace, pvcName string) ([]string, error) {
+	pods, err := ListPods(namespace)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	for _, pod := range pods {
+		if !podReferencesPVC(pod, pvcName) {
+			continue
+		}
+		finished := pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+		if finished && pod.DeletionTimestamp == nil {
+			continue
+		}
+		result = append(result, pod.Name)
+	}
+	return result, nil
+}
+
+// GetPod returns a pod
+func GetPod(namespace, name string) (*corev1.Pod, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// DeletePod deletes a pod, only if it is still the instance with the given UID
+func DeletePod(namespace, name string, uid types.UID) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+}
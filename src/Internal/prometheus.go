@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusTimeout bounds a single range query
+const prometheusTimeout = 60 * time.Second
+
+// volumeUsedBytesQuery returns the used bytes of every PVC as reported by the kubelet
+const volumeUsedBytesQuery = "max by (namespace, persistentvolumeclaim) (kubelet_volume_stats_used_bytes)"
+
+// VolumeUsageSample is a single used-bytes sample of a PVC
+type VolumeUsageSample struct {
+	At        time.Time
+	UsedBytes float64
+}
+
+type promRangeResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryVolumeUsageRange runs a Prometheus range query over the kubelet volume
+// stats and returns the used-bytes samples of each PVC, keyed by namespace/pvc
+func QueryVolumeUsageRange(client *http.Client, baseURL string, start, end time.Time, step time.Duration) (map[string][]VolumeUsageSample, error) {
+	if client == nil {
+		client = &http.Client{Timeout: prometheusTimeout}
+	}
+
+	query := url.Values{}
+	query.Set("query", volumeUsedBytesQuery)
+	query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	query.Set("step", strconv.Itoa(int(step.Seconds())))
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/api/v1/query_range?" + query.Encode()
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("querying Prometheus: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body promRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding Prometheus response (%s): %v", resp.Status, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query failed: %s", body.Error)
+	}
+
+	samples := map[string][]VolumeUsageSample{}
+	for _, series := range body.Data.Result {
+		key := series.Metric["namespace"] + "/" + series.Metric["persistentvolumeclaim"]
+		for _, v := range series.Values {
+			if len(v) != 2 {
+				continue
+			}
+			ts, ok := v[0].(float64)
+			if !ok {
+				continue
+			}
+			raw, ok := v[1].(string)
+			if !ok {
+				continue
+			}
+			used, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				continue
+			}
+			samples[key] = append(samples[key], VolumeUsageSample{
+				At:        time.Unix(0, int64(ts*float64(time.Second))),
+				UsedBytes: used,
+			})
+		}
+	}
+	return samples, nil
+}
+
+type promInstantResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryVolumeUsage runs an instant Prometheus query over the kubelet volume
+// stats and returns the current used bytes of each PVC, keyed by namespace/pvc
+func QueryVolumeUsage(client *http.Client, baseURL string) (map[string]float64, error) {
+	if client == nil {
+		client = &http.Client{Timeout: prometheusTimeout}
+	}
+
+	query := url.Values{}
+	query.Set("query", volumeUsedBytesQuery)
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/api/v1/query?" + query.Encode()
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("querying Prometheus: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body promInstantResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding Prometheus response (%s): %v", resp.Status, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query failed: %s", body.Error)
+	}
+
+	usage := map[string]float64{}
+	for _, series := range body.Data.Result {
+		if len(series.Value) != 2 {
+			continue
+		}
+		raw, ok := series.Value[1].(string)
+		if !ok {
+			continue
+		}
+		used, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+		usage[series.Metric["namespace"]+"/"+series.Metric["persistentvolumeclaim"]] = used
+	}
+	return usage, nil
+}
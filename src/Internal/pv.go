@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetPV returns a single PersistentVolume by name
+func GetPV(name string) (*corev1.PersistentVolume, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().PersistentVolumes().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// ListPVs returns all PersistentVolumes in the cluster
+func ListPVs() ([]corev1.PersistentVolume, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pvs.Items, nil
+}
+
+// PVSource returns the driver and backend volume handle of a PV, covering CSI
+// and the common in-tree volume plugins
+func PVSource(pv corev1.PersistentVolume) (driver, handle string) {
+	src := pv.Spec.PersistentVolumeSource
+	switch {
+	case src.CSI != nil:
+		return src.CSI.Driver, src.CSI.VolumeHandle
+	case src.AWSElasticBlockStore != nil:
+		return "kubernetes.io/aws-ebs", src.AWSElasticBlockStore.VolumeID
+	case src.GCEPersistentDisk != nil:
+		return "kubernetes.io/gce-pd", src.GCEPersistentDisk.PDName
+	case src.AzureDisk != nil:
+		return "kubernetes.io/azure-disk", src.AzureDisk.DataDiskURI
+	case src.AzureFile != nil:
+		return "kubernetes.io/azure-file", src.AzureFile.ShareName
+	case src.NFS != nil:
+		return "nfs", src.NFS.Server + ":" + src.NFS.Path
+	case src.Local != nil:
+		return "local", src.Local.Path
+	case src.HostPath != nil:
+		return "hostPath", src.HostPath.Path
+	default:
+		return "unknown", ""
+	}
+}
@@ -28,10 +28,35 @@ func ListPVCs(namespace string) ([]v1.PersistentVolumeClaim, error) {
 	return pvcs.Items, nil
 }
 func GetPVC(namespace, pvcName string) (*corev1.PersistentVolumeClaim, error) {
-	clientset, _ := GetK8sClient()
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
 }
 
+// DeletePVC deletes a PVC; the PV follows its reclaim policy
+func DeletePVC(namespace, pvcName string) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), pvcName, metav1.DeleteOptions{})
+}
+
+// DeletePVCUnchanged deletes a PVC only while it is the object that was read:
+// a claim recreated or modified since (other UID or resourceVersion) is kept
+// and a Conflict error returned
+func DeletePVCUnchanged(pvc corev1.PersistentVolumeClaim) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(context.TODO(), pvc.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &pvc.UID, ResourceVersion: &pvc.ResourceVersion},
+	})
+}
+
 func ExecCommandInPod(clientset *kubernetes.Clientset, config *rest.Config, podName, namespace string, command []string) (string, error) {
 	req := clientset.CoreV1().RESTClient().
 		Post().
@@ -64,6 +89,71 @@ func ExecCommandInPod(clientset *kubernetes.Clientset, config *rest.Config, podN
 	return stdout.String(), nil
 }
 
+// ExecInContainer runs a command in a container of a pod (the default
+// container when empty) until it exits or ctx is done, returning its stdout
+func ExecInContainer(ctx context.Context, namespace, podName, container string, command []string) (string, error) {
+	clientset, config, err := GetK8sClientWithConfig()
+	if err != nil {
+		return "", err
+	}
+	req := clientset.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr strings.Builder
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// FilesystemSizeInPod returns the size in bytes of the filesystem mounted at
+// mountPath in a pod container, as reported by df
+func FilesystemSizeInPod(ctx context.Context, namespace, podName, container, mountPath string) (int64, error) {
+	size, _, err := FilesystemUsageInPod(ctx, namespace, podName, container, mountPath)
+	return size, err
+}
+
+// FilesystemUsageInPod returns the size and the used bytes of the filesystem
+// mounted at mountPath in a pod container, as reported by df
+func FilesystemUsageInPod(ctx context.Context, namespace, podName, container, mountPath string) (size, used int64, err error) {
+	out, err := ExecInContainer(ctx, namespace, podName, container, []string{"df", "-Pk", mountPath})
+	if err != nil {
+		return 0, 0, err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 3 {
+		return 0, 0, fmt.Errorf("unexpected df output %q", out)
+	}
+	sizeKB, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected df output %q", out)
+	}
+	usedKB, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected df output %q", out)
+	}
+	return sizeKB * 1024, usedKB * 1024, nil
+}
+
 // GetUsedSizeInMBInPod executes du -sm inside a pod and returns used MB
 func GetUsedSizeInMBInPod(clientset *kubernetes.Clientset, config *rest.Config, podName, namespace, mountPath string) (int64, error) {
 	cmd := []string{"sh", "-c", fmt.Sprintf("du -sm %s 2>/dev/null || echo 0", mountPath)}
@@ -107,8 +197,7 @@ func GetUsedSizeInMBInPod(clientset *kubernetes.Clientset, config *rest.Config,
 	return usedMB, nil
 }
 
-// GetUsedSizeInMB sums used storage of PVC across all pods mounting it
-// GetUsedSizeInMB sums used storage of a PVC across all pods mounting it
+// GetUsedSizeInMB sums used storage of a PVC across all running pods mounting it
 func GetUsedSizeInMB(clientset *kubernetes.Clientset, config *rest.Config, namespace, pvcName string) (int64, error) {
 	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
@@ -117,6 +206,10 @@ func GetUsedSizeInMB(clientset *kubernetes.Clientset, config *rest.Config, names
 
 	var totalUsed int64
 	for _, pod := range pods.Items {
+		// only running pods can be exec'd into
+		if PodPhase(pod) != AttachmentRunning {
+			continue
+		}
 		for _, vol := range pod.Spec.Volumes {
 			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
 				// iterate containers
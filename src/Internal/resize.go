@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ListResourceQuotas returns the ResourceQuotas of a namespace
+func ListResourceQuotas(namespace string) ([]corev1.ResourceQuota, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return quotas.Items, nil
+}
+
+// PatchPVCStorageRequest sets spec.resources.requests.storage of a PVC, which
+// triggers volume expansion when the storage class allows it
+func PatchPVCStorageRequest(namespace, pvcName string, size resource.Quantity) (*corev1.PersistentVolumeClaim, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"requests": map[string]string{"storage": size.String()},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clientset.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), pvcName, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// PVCCondition returns the condition of the given type, nil if not set
+func PVCCondition(pvc corev1.PersistentVolumeClaim, conditionType corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaimCondition {
+	for i := range pvc.Status.Conditions {
+		if pvc.Status.Conditions[i].Type == conditionType && pvc.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &pvc.Status.Conditions[i]
+		}
+	}
+	return nil
+}
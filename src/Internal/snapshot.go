@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// VolumeSnapshotGVR is the snapshot.storage.k8s.io VolumeSnapshot resource
+var VolumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+// VolumeSnapshotContentGVR is the cluster-scoped VolumeSnapshotContent resource
+var VolumeSnapshotContentGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotcontents"}
+
+// ErrSnapshotAPIUnavailable is returned when the VolumeSnapshot CRDs are not installed
+var ErrSnapshotAPIUnavailable = fmt.Errorf("snapshot.storage.k8s.io/v1 API not available in this cluster")
+
+// VolumeSnapshotInfo is the subset of a VolumeSnapshot the auditor cares about
+type VolumeSnapshotInfo struct {
+	Namespace     string
+	Name          string
+	SourcePVC     string // spec.source.persistentVolumeClaimName
+	ClassName     string // spec.volumeSnapshotClassName
+	ContentName   string // status.boundVolumeSnapshotContentName
+	ReadyToUse    bool
+	RestoreSizeMB int64
+	CreatedAt     time.Time
+	UID           string
+}
+
+// ListVolumeSnapshots returns VolumeSnapshots in a namespace ("" for all namespaces)
+func ListVolumeSnapshots(namespace string) ([]VolumeSnapshotInfo, error) {
+	client, err := GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(VolumeSnapshotGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrSnapshotAPIUnavailable
+		}
+		return nil, fmt.Errorf("error listing volumesnapshots: %v", err)
+	}
+
+	snapshots := make([]VolumeSnapshotInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		snapshots = append(snapshots, volumeSnapshotFromUnstructured(item))
+	}
+	return snapshots, nil
+}
+
+func volumeSnapshotFromUnstructured(item unstructured.Unstructured) VolumeSnapshotInfo {
+	info := VolumeSnapshotInfo{
+		Namespace: item.GetNamespace(),
+		Name:      item.GetName(),
+		CreatedAt: item.GetCreationTimestamp().Time,
+		UID:       string(item.GetUID()),
+	}
+	info.SourcePVC, _, _ = unstructured.NestedString(item.Object, "spec", "source", "persistentVolumeClaimName")
+	info.ClassName, _, _ = unstructured.NestedString(item.Object, "spec", "volumeSnapshotClassName")
+	info.ContentName, _, _ = unstructured.NestedString(item.Object, "status", "boundVolumeSnapshotContentName")
+	info.ReadyToUse, _, _ = unstructured.NestedBool(item.Object, "status", "readyToUse")
+	if size, found, _ := unstructured.NestedString(item.Object, "status", "restoreSize"); found {
+		if q, err := resource.ParseQuantity(size); err == nil {
+			info.RestoreSizeMB = q.Value() / 1024 / 1024
+		}
+	}
+	return info
+}
+
+// VolumeSnapshotContentInfo is the subset of a VolumeSnapshotContent the auditor cares about
+type VolumeSnapshotContentInfo struct {
+	Name              string
+	Driver            string
+	DeletionPolicy    string // Delete or Retain
+	SnapshotHandle    string // status.snapshotHandle (backend snapshot ID)
+	SnapshotNamespace string // spec.volumeSnapshotRef.namespace
+	SnapshotName      string // spec.volumeSnapshotRef.name
+	SnapshotUID       string // spec.volumeSnapshotRef.uid
+	ReadyToUse        bool
+	RestoreSizeMB     int64
+	CreatedAt         time.Time
+}
+
+// ListVolumeSnapshotContents returns all VolumeSnapshotContents in the cluster
+func ListVolumeSnapshotContents() ([]VolumeSnapshotContentInfo, error) {
+	client, err := GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(VolumeSnapshotContentGVR).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrSnapshotAPIUnavailable
+		}
+		return nil, fmt.Errorf("error listing volumesnapshotcontents: %v", err)
+	}
+
+	contents := make([]VolumeSnapshotContentInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		info := VolumeSnapshotContentInfo{
+			Name:      item.GetName(),
+			CreatedAt: item.GetCreationTimestamp().Time,
+		}
+		info.Driver, _, _ = unstructured.NestedString(item.Object, "spec", "driver")
+		info.DeletionPolicy, _, _ = unstructured.NestedString(item.Object, "spec", "deletionPolicy")
+		info.SnapshotNamespace, _, _ = unstructured.NestedString(item.Object, "spec", "volumeSnapshotRef", "namespace")
+		info.SnapshotName, _, _ = unstructured.NestedString(item.Object, "spec", "volumeSnapshotRef", "name")
+		info.SnapshotUID, _, _ = unstructured.NestedString(item.Object, "spec", "volumeSnapshotRef", "uid")
+		info.SnapshotHandle, _, _ = unstructured.NestedString(item.Object, "status", "snapshotHandle")
+		info.ReadyToUse, _, _ = unstructured.NestedBool(item.Object, "status", "readyToUse")
+		if size, found, _ := unstructured.NestedInt64(item.Object, "status", "restoreSize"); found {
+			info.RestoreSizeMB = size / 1024 / 1024
+		}
+		contents = append(contents, info)
+	}
+	return contents, nil
+}
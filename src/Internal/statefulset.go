@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetStatefulSet returns a single StatefulSet by name
+func GetStatefulSet(namespace, name string) (*appsv1.StatefulSet, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// CreateStatefulSet creates a StatefulSet
+func CreateStatefulSet(sts *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.AppsV1().StatefulSets(sts.Namespace).Create(context.TODO(), sts, metav1.CreateOptions{})
+}
+
+// DeleteStatefulSetOrphan deletes a StatefulSet but leaves its pods and PVCs
+// running (kubectl delete --cascade=orphan); the UID precondition makes sure
+// a StatefulSet recreated in the meantime is not deleted
+func DeleteStatefulSetOrphan(sts appsv1.StatefulSet) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletePropagationOrphan
+	return clientset.AppsV1().StatefulSets(sts.Namespace).Delete(context.TODO(), sts.Name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     &metav1.Preconditions{UID: &sts.UID},
+	})
+}
+
+// ListStatefulSetPods returns the pods matching the selector of a StatefulSet
+func ListStatefulSetPods(sts appsv1.StatefulSet) ([]corev1.Pod, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of StatefulSet %s/%s: %v", sts.Namespace, sts.Name, err)
+	}
+	pods, err := clientset.CoreV1().Pods(sts.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods of StatefulSet %s/%s: %v", sts.Namespace, sts.Name, err)
+	}
+	return pods.Items, nil
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetStorageClass returns a single StorageClass by name
+func GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return clientset.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// ListStorageClasses returns all StorageClasses in the cluster
+func ListStorageClasses() ([]storagev1.StorageClass, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	classes, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return classes.Items, nil
+}
+
+// deprecatedInTreeProvisioners maps in-tree provisioners to their CSI replacement
+var deprecatedInTreeProvisioners = map[string]string{
+	"kubernetes.io/aws-ebs":         "ebs.csi.aws.com",
+	"kubernetes.io/gce-pd":          "pd.csi.storage.gke.io",
+	"kubernetes.io/azure-disk":      "disk.csi.azure.com",
+	"kubernetes.io/azure-file":      "file.csi.azure.com",
+	"kubernetes.io/cinder":          "cinder.csi.openstack.org",
+	"kubernetes.io/vsphere-volume":  "csi.vsphere.vmware.com",
+	"kubernetes.io/portworx-volume": "pxd.portworx.com",
+	"kubernetes.io/rbd":             "rbd.csi.ceph.com",
+	"kubernetes.io/glusterfs":       "",
+	"kubernetes.io/cephfs":          "cephfs.csi.ceph.com",
+	"kubernetes.io/storageos":       "",
+	"kubernetes.io/scaleio":         "",
+	"kubernetes.io/quobyte":         "",
+	"kubernetes.io/flocker":         "",
+}
+
+// DeprecatedProvisioner reports whether a provisioner is a deprecated in-tree
+// plugin, and the CSI driver that replaces it (empty if removed without replacement)
+func DeprecatedProvisioner(provisioner string) (string, bool) {
+	replacement, ok := deprecatedInTreeProvisioners[provisioner]
+	return replacement, ok
+}
+
+// IsDefaultStorageClass reports whether the class carries the default-class annotation
+func IsDefaultStorageClass(sc storagev1.StorageClass) bool {
+	return sc.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" ||
+		sc.Annotations["storageclass.beta.kubernetes.io/is-default-class"] == "true"
+}
+
+// AllowsExpansion reports whether PVCs of the class can be expanded
+func AllowsExpansion(sc storagev1.StorageClass) bool {
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+}
@@ -0,0 +1,315 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WorkloadRef is a workload that references a PVC
+type WorkloadRef struct {
+	Kind      string // Deployment, StatefulSet, DaemonSet, Job, CronJob, ReplicaSet or an owner kind
+	Name      string
+	Replicas  int32  // desired replicas (0 for scaled-down or suspended workloads)
+	Suspended bool   // CronJob suspended
+	Via       string // podTemplate, volumeClaimTemplate or ownerReference
+}
+
+func (w WorkloadRef) String() string {
+	state := fmt.Sprintf("%d replicas", w.Replicas)
+	if w.Suspended {
+		state = "suspended"
+	} else if w.Via == "ownerReference" {
+		state = "owner"
+	}
+	return fmt.Sprintf("%s/%s (%s)", w.Kind, w.Name, state)
+}
+
+// NamespaceWorkloads holds every workload kind that can reference a PVC in a namespace
+type NamespaceWorkloads struct {
+	Deployments  []appsv1.Deployment
+	StatefulSets []appsv1.StatefulSet
+	DaemonSets   []appsv1.DaemonSet
+	ReplicaSets  []appsv1.ReplicaSet
+	Jobs         []batchv1.Job
+	CronJobs     []batchv1.CronJob
+}
+
+// ListWorkloads lists Deployments, StatefulSets, DaemonSets, ReplicaSets, Jobs and CronJobs in a namespace
+func ListWorkloads(namespace string) (*NamespaceWorkloads, error) {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.TODO()
+	w := &NamespaceWorkloads{}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing deployments in %s: %v", namespace, err)
+	}
+	w.Deployments = deployments.Items
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing statefulsets in %s: %v", namespace, err)
+	}
+	w.StatefulSets = statefulSets.Items
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing daemonsets in %s: %v", namespace, err)
+	}
+	w.DaemonSets = daemonSets.Items
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing replicasets in %s: %v", namespace, err)
+	}
+	w.ReplicaSets = replicaSets.Items
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing jobs in %s: %v", namespace, err)
+	}
+	w.Jobs = jobs.Items
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing cronjobs in %s: %v", namespace, err)
+	}
+	w.CronJobs = cronJobs.Items
+
+	return w, nil
+}
+
+func templateReferencesPVC(spec corev1.PodSpec, pvcName string) bool {
+	for _, vol := range spec.Volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvcName {
+			return true
+		}
+	}
+	return false
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// StatefulSetOrdinal returns the ordinal if pvcName was created from one of the
+// StatefulSet's volumeClaimTemplates (<template>-<statefulset>-<ordinal>)
+func StatefulSetOrdinal(sts appsv1.StatefulSet, pvcName string) (int, bool) {
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		prefix := tpl.Name + "-" + sts.Name + "-"
+		if !strings.HasPrefix(pvcName, prefix) {
+			continue
+		}
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pvcName, prefix))
+		if err == nil && ordinal >= 0 {
+			return ordinal, true
+		}
+	}
+	return 0, false
+}
+
+// StatefulSetOrdinalStart returns the ordinal of the first replica
+// (spec.ordinals.start, 0 when unset)
+func StatefulSetOrdinalStart(sts appsv1.StatefulSet) int {
+	if sts.Spec.Ordinals == nil {
+		return 0
+	}
+	return int(sts.Spec.Ordinals.Start)
+}
+
+// ReferencesFor returns every workload that references the PVC through its pod
+// template, a StatefulSet volumeClaimTemplate, or the PVC's ownerReferences
+func (w *NamespaceWorkloads) ReferencesFor(pvc corev1.PersistentVolumeClaim) []WorkloadRef {
+	refs := []WorkloadRef{}
+	if w == nil {
+		return refs
+	}
+	seen := map[string]bool{}
+	add := func(ref WorkloadRef) {
+		key := ref.Kind + "/" + ref.Name
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, d := range w.Deployments {
+		if templateReferencesPVC(d.Spec.Template.Spec, pvc.Name) {
+			add(WorkloadRef{Kind: "Deployment", Name: d.Name, Replicas: replicasOrDefault(d.Spec.Replicas), Via: "podTemplate"})
+		}
+	}
+	for _, sts := range w.StatefulSets {
+		if templateReferencesPVC(sts.Spec.Template.Spec, pvc.Name) {
+			add(WorkloadRef{Kind: "StatefulSet", Name: sts.Name, Replicas: replicasOrDefault(sts.Spec.Replicas), Via: "podTemplate"})
+		}
+		if _, ok := StatefulSetOrdinal(sts, pvc.Name); ok {
+			add(WorkloadRef{Kind: "StatefulSet", Name: sts.Name, Replicas: replicasOrDefault(sts.Spec.Replicas), Via: "volumeClaimTemplate"})
+		}
+	}
+	for _, ds := range w.DaemonSets {
+		if templateReferencesPVC(ds.Spec.Template.Spec, pvc.Name) {
+			add(WorkloadRef{Kind: "DaemonSet", Name: ds.Name, Replicas: ds.Status.DesiredNumberScheduled, Via: "podTemplate"})
+		}
+	}
+	for _, rs := range w.ReplicaSets {
+		if templateReferencesPVC(rs.Spec.Template.Spec, pvc.Name) {
+			add(WorkloadRef{Kind: "ReplicaSet", Name: rs.Name, Replicas: replicasOrDefault(rs.Spec.Replicas), Via: "podTemplate"})
+		}
+	}
+	for _, job := range w.Jobs {
+		if templateReferencesPVC(job.Spec.Template.Spec, pvc.Name) {
+			add(WorkloadRef{Kind: "Job", Name: job.Name, Replicas: job.Status.Active, Via: "podTemplate"})
+		}
+	}
+	for _, cj := range w.CronJobs {
+		if templateReferencesPVC(cj.Spec.JobTemplate.Spec.Template.Spec, pvc.Name) {
+			suspended := cj.Spec.Suspend != nil && *cj.Spec.Suspend
+			add(WorkloadRef{Kind: "CronJob", Name: cj.Name, Suspended: suspended, Via: "podTemplate"})
+		}
+	}
+
+	// ownerReferences (StatefulSet retention policy, operators, ...) — the
+	// garbage collector removes the PVC once the owner is gone
+	for _, owner := range pvc.OwnerReferences {
+		if owner.Kind == "Pod" {
+			continue
+		}
+		add(WorkloadRef{Kind: owner.Kind, Name: owner.Name, Via: "ownerReference"})
+	}
+
+	return refs
+}
+
+// ScaleDownLeftover is a StatefulSet PVC whose ordinal is outside the current
+// replica range [start, start+replicas)
+type ScaleDownLeftover struct {
+	StatefulSet string
+	Ordinal     int
+	Start       int // spec.ordinals.start
+	Replicas    int32
+}
+
+// BelowStart reports whether the PVC was left behind by raising the start
+// ordinal rather than by lowering the replicas
+func (l ScaleDownLeftover) BelowStart() bool {
+	return l.Ordinal < l.Start
+}
+
+func (l ScaleDownLeftover) String() string {
+	switch {
+	case l.BelowStart():
+		return fmt.Sprintf("leftover of StatefulSet/%s (ordinal %d < start ordinal %d)", l.StatefulSet, l.Ordinal, l.Start)
+	case l.Start > 0:
+		return fmt.Sprintf("scale-down leftover of StatefulSet/%s (ordinal %d ≥ start %d + %d replicas)", l.StatefulSet, l.Ordinal, l.Start, l.Replicas)
+	}
+	return fmt.Sprintf("scale-down leftover of StatefulSet/%s (ordinal %d ≥ %d replicas)", l.StatefulSet, l.Ordinal, l.Replicas)
+}
+
+// ScaleDownLeftoverFor reports whether the PVC was left behind by a StatefulSet
+// scale-down: its ordinal is at or above start+replicas, or below the start
+// ordinal after spec.ordinals.start was raised. PVCs covered by a
+// WhenScaled=Delete retention policy are removed by the StatefulSet controller
+// and are not reported; StatefulSets scaled to zero are treated as dormant
+// rather than scaled down.
+func (w *NamespaceWorkloads) ScaleDownLeftoverFor(pvcName string) (ScaleDownLeftover, bool) {
+	if w == nil {
+		return ScaleDownLeftover{}, false
+	}
+	for _, sts := range w.StatefulSets {
+		ordinal, ok := StatefulSetOrdinal(sts, pvcName)
+		if !ok {
+			continue
+		}
+		replicas := replicasOrDefault(sts.Spec.Replicas)
+		start := StatefulSetOrdinalStart(sts)
+		if replicas == 0 || (ordinal >= start && ordinal < start+int(replicas)) {
+			continue
+		}
+		if policy := sts.Spec.PersistentVolumeClaimRetentionPolicy; policy != nil && policy.WhenScaled == appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+			continue
+		}
+		return ScaleDownLeftover{StatefulSet: sts.Name, Ordinal: ordinal, Start: start, Replicas: replicas}, true
+	}
+	return ScaleDownLeftover{}, false
+}
+
+// ScaleWorkload sets the replicas of a Deployment, StatefulSet or ReplicaSet
+// through its scale subresource
+func ScaleWorkload(namespace, kind, name string, replicas int32) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	apps := clientset.AppsV1()
+
+	var scale *autoscalingv1.Scale
+	switch kind {
+	case "Deployment":
+		scale, err = apps.Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	case "StatefulSet":
+		scale, err = apps.StatefulSets(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	case "ReplicaSet":
+		scale, err = apps.ReplicaSets(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	default:
+		return fmt.Errorf("%s/%s cannot be scaled", kind, name)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading scale of %s %s/%s: %v", kind, namespace, name, err)
+	}
+	scale.Spec.Replicas = replicas
+
+	switch kind {
+	case "Deployment":
+		_, err = apps.Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	case "ReplicaSet":
+		_, err = apps.ReplicaSets(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error scaling %s %s/%s to %d: %v", kind, namespace, name, replicas, err)
+	}
+	return nil
+}
+
+// SetCronJobSuspend suspends or resumes a CronJob
+func SetCronJobSuspend(namespace, name string, suspend bool) error {
+	clientset, err := GetK8sClient()
+	if err != nil {
+		return err
+	}
+	patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+	if _, err := clientset.BatchV1().CronJobs(namespace).Patch(context.TODO(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error setting suspend=%t on CronJob %s/%s: %v", suspend, namespace, name, err)
+	}
+	return nil
+}
+
+// IsControlled reports whether a ReplicaSet is managed by a controller (a Deployment)
+func (w *NamespaceWorkloads) IsControlled(kind, name string) bool {
+	if w == nil || kind != "ReplicaSet" {
+		return false
+	}
+	for _, rs := range w.ReplicaSets {
+		if rs.Name == name {
+			return metav1.GetControllerOf(&rs) != nil
+		}
+	}
+	return false
+}
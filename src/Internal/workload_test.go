@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testStatefulSet(replicas int32, start int32, whenScaled appsv1.PersistentVolumeClaimRetentionPolicyType) appsv1.StatefulSet {
+	sts := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+		},
+	}
+	if start > 0 {
+		sts.Spec.Ordinals = &appsv1.StatefulSetOrdinals{Start: start}
+	}
+	if whenScaled != "" {
+		sts.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{WhenScaled: whenScaled}
+	}
+	return sts
+}
+
+func TestScaleDownLeftoverFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		sts        appsv1.StatefulSet
+		pvc        string
+		leftover   bool
+		belowStart bool
+	}{
+		{"replica in range", testStatefulSet(3, 0, ""), "data-db-2", false, false},
+		{"ordinal at replicas", testStatefulSet(3, 0, ""), "data-db-3", true, false},
+		{"scaled to zero is dormant", testStatefulSet(0, 0, ""), "data-db-0", false, false},
+		{"WhenScaled=Delete", testStatefulSet(1, 0, appsv1.DeletePersistentVolumeClaimRetentionPolicyType), "data-db-1", false, false},
+		{"not a claim of the StatefulSet", testStatefulSet(1, 0, ""), "data-other-5", false, false},
+		{"in range with start", testStatefulSet(2, 5, ""), "data-db-6", false, false},
+		{"ordinal at start+replicas", testStatefulSet(2, 5, ""), "data-db-7", true, false},
+		{"ordinal below start", testStatefulSet(2, 5, ""), "data-db-0", true, true},
+		{"ordinal below start, below replicas", testStatefulSet(3, 5, ""), "data-db-1", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &NamespaceWorkloads{StatefulSets: []appsv1.StatefulSet{tt.sts}}
+			leftover, ok := w.ScaleDownLeftoverFor(tt.pvc)
+			if ok != tt.leftover {
+				t.Fatalf("ScaleDownLeftoverFor(%s) = %v, want %v", tt.pvc, ok, tt.leftover)
+			}
+			if ok && leftover.BelowStart() != tt.belowStart {
+				t.Errorf("BelowStart() = %v, want %v (%s)", leftover.BelowStart(), tt.belowStart, leftover)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	applyDryRun  bool
+	applyYes     bool
+	applyTimeout time.Duration
+)
+
+// Outcomes of applying a plan item
+const (
+	ApplyApplied  = "applied"
+	ApplySkipped  = "skipped"
+	ApplyDeclined = "declined"
+	ApplyFailed   = "failed"
+)
+
+// ApplyResult is the outcome of a single plan item
+type ApplyResult struct {
+	Item    PlanItem
+	Status  string
+	Message string
+}
+
+// RevalidatePlanItem checks a plan item against the live cluster before it is
+// executed. It returns the live PVC and notes about harmless drift, or an
+// error when the item no longer holds (recreated PVC, changed size, usage
+// grown past the target, new pods or workload references, or a deletion
+// risk — see DeleteBlocker).
+func RevalidatePlanItem(item PlanItem, measureUsage func(ns, pvc string) (int64, error), assess RiskAssessor) (*corev1.PersistentVolumeClaim, []string, error) {
+	var notes []string
+
+	pvc, err := internal.GetPVC(item.Namespace, item.PVC)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PVC no longer readable: %v", err)
+	}
+	if item.UID != "" && string(pvc.UID) != item.UID {
+		return nil, nil, fmt.Errorf("PVC was recreated since the plan (uid %s, planned %s)", pvc.UID, item.UID)
+	}
+	if pvc.ResourceVersion != item.ResourceVersion {
+		current := CurrentSize(*pvc)
+		if current.Value()/1024/1024 != item.CurrentMB {
+			return nil, nil, fmt.Errorf("PVC drifted since the plan: size is %s, planned from %s", current.String(), item.CurrentSize)
+		}
+		notes = append(notes, fmt.Sprintf("resourceVersion changed (%s → %s), size unchanged", item.ResourceVersion, pvc.ResourceVersion))
+	}
+
+	switch item.Action {
+	case ActionExpand:
+		target := resource.MustParse(item.TargetSize)
+		if current := CurrentSize(*pvc); current.Cmp(target) >= 0 {
+			return nil, nil, fmt.Errorf("already at %s, not below the target %s", current.String(), item.TargetSize)
+		}
+	case ActionMigrate:
+		usedMB, err := measureUsage(item.Namespace, item.PVC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("current usage cannot be measured: %v", err)
+		}
+		if needed := int64(float64(usedMB) * (1 + headroomPct/100)); needed > item.TargetMB {
+			return nil, nil, fmt.Errorf("usage grew to %d MB since the plan, %s leaves less than %.0f%% headroom", usedMB, item.TargetSize, headroomPct)
+		}
+		if usedMB != item.UsedMB {
+			notes = append(notes, fmt.Sprintf("usage changed from %d MB to %d MB", item.UsedMB, usedMB))
+		}
+	case ActionDelete:
+		attachments, err := internal.FindPodAttachmentsForPVC(item.Namespace, item.PVC)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(attachments) > 0 {
+			return nil, nil, fmt.Errorf("pod %s now references the PVC", attachments[0].PodName)
+		}
+		workloads, err := internal.ListWorkloads(item.Namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		if refs := workloads.ReferencesFor(*pvc); len(refs) > 0 {
+			return nil, nil, fmt.Errorf("%s now references the PVC", refs[0].String())
+		}
+		risk, err := assess(item.Namespace, item.PVC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("deletion risk not assessed: %v", err)
+		}
+		if blocker := DeleteBlocker(risk); blocker != "" {
+			return nil, nil, fmt.Errorf("not deleted: %s", blocker)
+		}
+	}
+	return pvc, notes, nil
+}
+
+// PlanDiffLine renders a plan item as a diff line: ~ expand, ± migrate, - delete
+func PlanDiffLine(item PlanItem) string {
+	switch item.Action {
+	case ActionExpand:
+		return fmt.Sprintf("  ~ %s/%s  %s → %s  (expand: %s)", item.Namespace, item.PVC, item.CurrentSize, item.TargetSize, item.Reason)
+	case ActionMigrate:
+		return fmt.Sprintf("  ± %s/%s  %s → %s  (migrate: %s)", item.Namespace, item.PVC, item.CurrentSize, item.TargetSize, item.Reason)
+	case ActionDelete:
+		return fmt.Sprintf("  - %s/%s  %s  (delete: %s)", item.Namespace, item.PVC, item.CurrentSize, item.Reason)
+	default:
+		return fmt.Sprintf("    %s/%s  (skip: %s)", item.Namespace, item.PVC, item.Reason)
+	}
+}
+
+// executePlanItem runs the action of a revalidated plan item
+func executePlanItem(item PlanItem, pvc *corev1.PersistentVolumeClaim, hooks *Hooks, logf Logf) (string, error) {
+	switch item.Action {
+	case ActionExpand:
+		var result ResizeResult
+		payload := HookPayload{Action: ActionExpand, From: item.CurrentSize, To: item.TargetSize}
+		err := hooks.Run(*pvc, payload, logf, func() error {
+			var err error
+			result, err = ExpandPVC(item.Namespace, item.PVC, resource.MustParse(item.TargetSize), applyTimeout, logf)
+			return err
+		})
+		if err != nil {
+			return result.Phase, err
+		}
+		if err := settleFilesystem(&result, resizeRestart, applyTimeout, logf); err != nil {
+			return result.Filesystem.Status, changeMade(err)
+		}
+		msg := fmt.Sprintf("%s → %s (%s)", result.From.String(), result.To.String(), result.Phase)
+		if result.Filesystem.Status != "" {
+			msg += ", filesystem " + result.Filesystem.Status
+		}
+		return msg, nil
+	case ActionMigrate:
+		spec := MigrateSpec{
+			Namespace: item.Namespace,
+			PVC:       item.PVC,
+			Size:      resource.MustParse(item.TargetSize),
+			CopyImage: migrateCopyImage,
+			Timeout:   migrateTimeout,
+		}
+		var result MigrateResult
+		err := hooks.Run(*pvc, migratePayload(*pvc, spec), logf, func() error {
+			var err error
+			result, err = MigratePVC(spec, logf)
+			return err
+		})
+		if err != nil {
+			return result.Phase, err
+		}
+		return fmt.Sprintf("%s → %s on PV %s, old PV %s retained (%s)", result.From.String(), result.To.String(), result.NewPV, result.OldPV, result.Verification), nil
+	case ActionDelete:
+		reclaim := "Delete"
+		if pvc.Spec.VolumeName != "" {
+			if pv, err := internal.GetPV(pvc.Spec.VolumeName); err == nil {
+				reclaim = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			}
+		}
+		// the claim revalidated before the prompt is the only one deleted
+		if err := internal.DeletePVCUnchanged(*pvc); err != nil {
+			if apierrors.IsConflict(err) {
+				return "", fmt.Errorf("PVC %s/%s changed since it was revalidated, not deleted: %v", item.Namespace, item.PVC, err)
+			}
+			return "", err
+		}
+		return fmt.Sprintf("deleted, PV %s reclaim policy %s", displayOrDash(pvc.Spec.VolumeName), reclaim), nil
+	}
+	return "", nil
+}
+
+// planItemChange describes a plan item for the guard
+func planItemChange(item PlanItem) Change {
+	change := Change{Namespace: item.Namespace, Name: item.PVC, Action: item.Action}
+	switch item.Action {
+	case ActionExpand:
+		change.Bytes = (item.TargetMB - item.CurrentMB) * 1024 * 1024
+	case ActionMigrate:
+		change.Bytes = (item.CurrentMB - item.TargetMB) * 1024 * 1024
+	case ActionDelete:
+		change.Bytes = item.CurrentMB * 1024 * 1024
+	}
+	return change
+}
+
+// confirm asks a per-item question; "a" approves the remaining items, "q" stops
+func confirm(reader *bufio.Reader, question string) (yes, all, quit bool) {
+	fmt.Printf("%s [y/N/a(ll)/q(uit)]: ", question)
+	answer, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, false, false
+	case "a", "all":
+		return true, true, false
+	case "q", "quit":
+		return false, false, true
+	}
+	return false, false, false
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan>",
+	Short: "Re-validate and execute a plan written by `plan`, with a dry-run diff and per-item confirmation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := LoadPlan(args[0])
+		if err != nil {
+			return err
+		}
+		if cluster := internal.GetClusterName(); plan.ClusterName != "" && cluster != plan.ClusterName {
+			return fmt.Errorf("plan was made for cluster %s, current context is %s", plan.ClusterName, cluster)
+		}
+
+		var items []PlanItem
+		for _, item := range plan.Items {
+			if item.Action != ActionSkip {
+				items = append(items, item)
+			}
+		}
+		fmt.Printf("📋 Plan %s (%s): %d change(s)\n", args[0], plan.GeneratedAt, len(items))
+		for _, item := range items {
+			fmt.Println(PlanDiffLine(item))
+		}
+		if applyDryRun || len(items) == 0 {
+			return nil
+		}
+		guard, hooks, err := newCommandGuard()
+		if err != nil {
+			return err
+		}
+
+		assess := NewRiskAssessor(protectedPatterns)
+		usage := func(ns, pvc string) (int64, error) {
+			used, err := measureUsedBytes(ns, pvc)
+			return used / (1024 * 1024), err
+		}
+
+		logf := func(format string, args ...interface{}) {
+			fmt.Printf("  ↳ "+format+"\n", args...)
+		}
+		reader := bufio.NewReader(os.Stdin)
+		approveAll := applyYes
+		var results []ApplyResult
+		for i, item := range items {
+			fmt.Printf("\n[%d/%d] %s\n", i+1, len(items), strings.TrimSpace(PlanDiffLine(item)))
+
+			pvc, notes, err := RevalidatePlanItem(item, usage, assess)
+			if err != nil {
+				fmt.Printf("  ⏭️  skipped: %v\n", err)
+				results = append(results, ApplyResult{Item: item, Status: ApplySkipped, Message: err.Error()})
+				continue
+			}
+			for _, note := range notes {
+				fmt.Printf("  ℹ️  %s\n", note)
+			}
+
+			if !approveAll {
+				yes, all, quit := confirm(reader, fmt.Sprintf("  Apply %s of %s/%s?", item.Action, item.Namespace, item.PVC))
+				if quit {
+					break
+				}
+				if !yes {
+					results = append(results, ApplyResult{Item: item, Status: ApplyDeclined})
+					continue
+				}
+				approveAll = all
+			}
+
+			var msg string
+			err = guard.Run(planItemChange(item), logf, func() error {
+				var err error
+				msg, err = executePlanItem(item, pvc, hooks, logf)
+				return err
+			})
+			if err != nil {
+				fmt.Printf("  ❌ %v\n", err)
+				results = append(results, ApplyResult{Item: item, Status: ApplyFailed, Message: err.Error()})
+				continue
+			}
+			fmt.Printf("  ✅ %s\n", msg)
+			results = append(results, ApplyResult{Item: item, Status: ApplyApplied, Message: msg})
+		}
+
+		counts := map[string]int{}
+		for _, r := range results {
+			counts[r.Status]++
+		}
+		fmt.Printf("\nApplied %d, skipped %d, declined %d, failed %d, not reached %d\n",
+			counts[ApplyApplied], counts[ApplySkipped], counts[ApplyDeclined], counts[ApplyFailed], len(items)-len(results))
+		if counts[ApplyFailed] > 0 {
+			return fmt.Errorf("%d plan item(s) failed", counts[ApplyFailed])
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Only show the changes the plan would make")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Apply every re-validated item without asking")
+	applyCmd.Flags().DurationVar(&applyTimeout, "timeout", 5*time.Minute, "How long to follow each expansion")
+	applyCmd.Flags().BoolVar(&resizeRestart, "restart-pod", false, "Restart pods whose filesystem did not grow after an expansion (CSI drivers expanding offline only)")
+	applyCmd.Flags().StringVar(&migrateCopyImage, "copy-image", "alpine:3.20", "Image of the migration copy jobs")
+	applyCmd.Flags().DurationVar(&migrateTimeout, "copy-timeout", time.Hour, "How long each migration copy job may run")
+	applyCmd.Flags().StringSliceVar(&protectedPatterns, "protected", []string{"prod*", "*-prod", "production"}, "Namespace globs whose PVCs are never deleted")
+	addGuardFlags(applyCmd)
+	applyCmd.Flags().Float64Var(&headroomPct, "headroom", 20, "Headroom a migration target must leave above the current usage (%)")
+}
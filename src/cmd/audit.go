@@ -12,6 +12,8 @@ import (
 	"pvc-audit/util"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 )
 
 // ANSI colors for categories
@@ -21,8 +23,20 @@ func ColorizeCategory(cat string) string {
 		return "\033[41;37m Critical \033[0m" // red bg, white text
 	case "Over-provisioned":
 		return "\033[43;30m Overprovisioned \033[0m" // yellow bg, black text
-	case "Unused":
-		return "\033[44;37m Unused \033[0m" // blue bg, white text
+	case "Idle":
+		return "\033[44;37m Idle \033[0m" // blue bg, white text
+	case "Abandoned":
+		return "\033[45;37m Abandoned \033[0m" // magenta bg, white text
+	case "Dormant":
+		return "\033[47;30m Dormant \033[0m" // white bg, black text
+	case "Orphaned":
+		return "\033[100;37m Orphaned \033[0m" // grey bg, white text
+	case "Scale-down leftover":
+		return "\033[41;30m Scale-down leftover \033[0m" // red bg, black text
+	case "Filling fast":
+		return "\033[101;37m Filling fast \033[0m" // bright red bg, white text
+	case "Newly provisioned":
+		return "\033[46;30m Newly provisioned \033[0m" // cyan bg, black text
 	case "Healthy":
 		return "\033[42;30m Healthy \033[0m" // green bg, black text
 	default:
@@ -64,26 +78,42 @@ func GenerateCLIAuditReport(clusterReport ClusterReport) string {
 	if clusterReport.TotalAllocatedGB > 0 {
 		wastagePct = (clusterReport.TotalWastedGB / clusterReport.TotalAllocatedGB) * 100
 	}
-	report.WriteString(fmt.Sprintf("Wastage Percentage          : %.1f%%\n\n", wastagePct))
+	report.WriteString(fmt.Sprintf("Wastage Percentage          : %.1f%%\n", wastagePct))
+	reclaimVal, reclaimUnit := util.FormatSizeMBorGB(clusterReport.TotalReclaimableMB)
+	report.WriteString(fmt.Sprintf("Reclaimable by Right-sizing : %.2f %s\n", reclaimVal, reclaimUnit))
+	if clusterReport.LowConfidenceMB > 0 {
+		lowVal, lowUnit := util.FormatSizeMBorGB(clusterReport.LowConfidenceMB)
+		report.WriteString(fmt.Sprintf("  ↳ not counted, unmeasured  : %.2f %s (low confidence, no running pod)\n", lowVal, lowUnit))
+	}
+	report.WriteString("\n")
 
 	report.WriteString("⚠️ PVC Wastage Details\n")
 	report.WriteString("─────────────────────────────────────────────\n")
 	report.WriteString(fmt.Sprintf("PVCs with High Wastage (≥80%%) : %d\n", clusterReport.PVCsWithWastage))
 	report.WriteString(fmt.Sprintf("Unattached PVCs                : %d\n", len(clusterReport.UnattachedPVCs)))
-	report.WriteString(fmt.Sprintf("Cleanup Candidates             : %d\n\n", len(clusterReport.CleanupCandidates)))
+	report.WriteString(fmt.Sprintf("  ↳ Dormant (workload exists)  : %d\n", len(clusterReport.DormantPVCs)))
+	report.WriteString(fmt.Sprintf("  ↳ Orphaned (no references)   : %d\n", len(clusterReport.OrphanedPVCs)))
+	report.WriteString(fmt.Sprintf("  ↳ StatefulSet leftovers      : %d\n", len(clusterReport.ScaleDownLeftovers)))
+	report.WriteString(fmt.Sprintf("Cleanup Candidates             : %d\n", len(clusterReport.CleanupCandidates)))
+	report.WriteString(fmt.Sprintf("Filling Fast PVCs              : %d\n", len(clusterReport.FillingFastPVCs)))
+	report.WriteString(fmt.Sprintf("Pending / Lost PVCs            : %d / %d\n", clusterReport.PendingPVCs, clusterReport.LostPVCs))
+	report.WriteString(fmt.Sprintf("Flagged PersistentVolumes      : %d\n", len(clusterReport.PVFindings)))
+	report.WriteString(fmt.Sprintf("Suppressed Findings            : %d\n\n", len(clusterReport.SuppressedPVCs)))
 
 	report.WriteString("📋 Top 5 High Wastage PVCs\n")
 	report.WriteString("──────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────\n")
-	report.WriteString(fmt.Sprintf("| %-15s | %-33s | %-10s | %-9s | %-9s | %-9s | %-12s | %-15s |\n",
-		"Namespace", "PVC Name", "Allocated", "Used", "Wasted", "Used (%)", "Wastage (%)", "Category"))
+	report.WriteString(fmt.Sprintf("| %-15s | %-33s | %-10s | %-9s | %-9s | %-9s | %-12s | %-16s | %-15s |\n",
+		"Namespace", "PVC Name", "Allocated", "Used", "Wasted", "Used (%)", "Wastage (%)", "Recommended", "Category"))
 	report.WriteString("──────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────────\n")
 	count := 0
 	for _, nsReport := range clusterReport.NamespaceReports {
 		for _, pvc := range nsReport.PVCs {
-			if pvc.WastagePct >= 80 {
+			if pvc.WastagePct >= 80 && !pvc.Suppressed && IsFlaggable(pvc.Category) {
 				allocVal, usedVal, wastedVal, unit := util.FormatSize(pvc.AllocatedMB, pvc.UsedMB)
 
-				report.WriteString(fmt.Sprintf("| %-15s | %-33s | %7.2f %-2s | %6.2f %-2s | %6.2f %-2s | %7d %% | %10d %% | %-15s |\n",
+				recVal, recUnit := util.FormatSizeMBorGB(pvc.RecommendedMB)
+
+				report.WriteString(fmt.Sprintf("| %-15s | %-33s | %7.2f %-2s | %6.2f %-2s | %6.2f %-2s | %7d %% | %10d %% | %7.2f %-2s (%-4s) | %-15s |\n",
 					nsReport.Namespace,
 					pvc.Name,
 					allocVal, unit,
@@ -91,6 +121,7 @@ func GenerateCLIAuditReport(clusterReport ClusterReport) string {
 					wastedVal, unit,
 					pvc.UsedPct,
 					pvc.WastagePct,
+					recVal, recUnit, pvc.Confidence,
 					ColorizeCategory(pvc.Category),
 				))
 
@@ -105,153 +136,474 @@ func GenerateCLIAuditReport(clusterReport ClusterReport) string {
 		}
 	}
 
+	report.WriteString(ForecastSummary(clusterReport))
+	report.WriteString(CostSummary(clusterReport))
+	report.WriteString(HealthSummary(clusterReport))
+	report.WriteString(ScaleDownLeftoverSummary(clusterReport))
+	report.WriteString(PVFindingsSummary(clusterReport))
+	report.WriteString(SnapshotSummaryReport(clusterReport))
+	report.WriteString(SuppressionSummary(clusterReport))
+	report.WriteString(BaselineSummary(clusterReport.Baseline))
+
 	report.WriteString(fmt.Sprintf("\n📄 Detailed CSV Report: %s\n", clusterReport.CSVFilePath))
+	report.WriteString(fmt.Sprintf("📄 JSON Report (baseline): %s\n", clusterReport.JSONFilePath))
 	report.WriteString("─────────────────────────────────────────────\n")
 	report.WriteString("✅ Audit completed successfully.\n")
 
 	return report.String()
 }
 
-var pushgatewayServer string
+// RunAudit measures and classifies every PVC of the given namespaces and
+// returns the cluster report together with the CSV rows (header first).
+// Report file paths and the baseline comparison are left to the caller.
+func RunAudit(namespaces []string) (ClusterReport, [][]string, error) {
+	suppressions, err := LoadSuppressions(suppressionsFile)
+	if err != nil {
+		return ClusterReport{}, nil, err
+	}
+	catalog, err := LoadPriceCatalog(pricingFile)
+	if err != nil {
+		return ClusterReport{}, nil, err
+	}
 
-var auditCmd = &cobra.Command{
-	Use:   "audit",
-	Short: "Audit PVCs and generate wastage report",
-	RunE: func(cmd *cobra.Command, args []string) error {
+	// real PV costs from OpenCost; the catalog prices whatever it does not cover
+	var openCostPVs map[string]Internal.OpenCostPV
+	var costWindow time.Duration
+	if openCostURL != "" {
+		costWindow, err = ParseCostWindow(openCostWindow)
+		if err != nil {
+			return ClusterReport{}, nil, err
+		}
+		openCostPVs, err = Internal.FetchOpenCostPVCosts(nil, openCostURL, openCostWindow)
+		if err != nil {
+			fmt.Printf("⚠️  OpenCost unavailable, falling back to the price catalog: %v\n", err)
+		}
+	}
+	pricingEnabled := catalog != nil || openCostURL != ""
+	now := time.Now()
 
-		// Determine namespaces
-		var namespaces []string
-		if allNamespaces {
-			nsList, err := Internal.ListNamespaces()
-			if err != nil {
-				return err
-			}
-			namespaces = nsList
-		} else {
-			namespaces = []string{namespace}
+	// usage history for growth forecasts: saved reports and/or Prometheus
+	history := UsageHistory{}
+	if historyDir != "" {
+		h, err := LoadReportHistory(historyDir, now.Add(-historyWindow))
+		if err != nil {
+			fmt.Printf("Error loading report history: %v\n", err)
+		}
+		history.Merge(h)
+	}
+	if prometheusURL != "" {
+		h, err := LoadPrometheusHistory(prometheusURL, now.Add(-historyWindow), now)
+		if err != nil {
+			fmt.Printf("⚠️  Prometheus history unavailable: %v\n", err)
 		}
+		history.Merge(h)
+	}
+
+	clusterName := Internal.GetClusterName()
+	clientset, config, err := Internal.GetK8sClientWithConfig()
+	if err != nil {
+		return ClusterReport{}, nil, err
+	}
 
-		clusterName := Internal.GetClusterName()
-		clientset, config, err := Internal.GetK8sClientWithConfig()
+	// storage classes, for provisioner sizing rules
+	storageClasses := map[string]storagev1.StorageClass{}
+	if classes, err := Internal.ListStorageClasses(); err == nil {
+		for _, sc := range classes {
+			storageClasses[sc.Name] = sc
+		}
+	} else {
+		fmt.Printf("Error listing storage classes: %v\n", err)
+	}
+
+	// snapshot accounting; PVC-level numbers are joined in below
+	var snapshotScope []string
+	if !allNamespaces {
+		snapshotScope = namespaces
+	}
+	snapshots, err := CollectSnapshotAccounting(snapshotScope, snapshotRetention, now)
+	if err != nil {
+		if err != Internal.ErrSnapshotAPIUnavailable {
+			fmt.Printf("Error collecting VolumeSnapshots: %v\n", err)
+		}
+		snapshots = &SnapshotAccounting{ByPVC: map[string]SnapshotSummary{}}
+	}
+
+	var namespaceReports []NamespaceReport
+	var csvRows [][]string
+	csvRows = append(csvRows, []string{"Namespace", "PVC Name", "Allocated", "Used", "Wasted", "Used(%)", "Wastage(%)", "Attached Pod", "Pod Phase", "Referenced By", "Category", "Age", "Last Activity", "Growth/Day", "Days Until Full", "Full Date", "Recommended", "Reclaimable", "Confidence", "Monthly Cost", "Wasted Cost", "Potential Savings", "Cost Source", "Snapshots", "Snapshot Size", "Suppressed", "Suppression Reason", "Suppression Owner", "Suppression Expires"})
+
+	var highWastagePVCs, unattachedPVCs, cleanupCandidates, suppressedPVCs, expiredSuppressed []PVCInfo
+	var dormantPVCs, orphanedPVCs, scaleDownLeftovers, fillingFastPVCs []PVCInfo
+	var unhealthyPVCs []PVCHealth
+	var pendingPVCs, lostPVCs, unpricedPVCs int
+	var totalMonthlyCost, totalWastedCost, totalSavingsCost float64
+	var totalPVCs, totalNamespaces int
+	var totalAllocatedMB, totalUsedMB, totalWastedMB, totalReclaimableMB, lowConfidenceMB int64
+
+	for _, ns := range namespaces {
+		pvcs, err := Internal.ListPVCs(ns)
 		if err != nil {
-			return err
+			fmt.Printf("Error listing PVCs in namespace %s: %v\n", ns, err)
+			continue
+		}
+		if len(pvcs) == 0 {
+			continue
 		}
 
-		var namespaceReports []NamespaceReport
-		var csvRows [][]string
-		csvRows = append(csvRows, []string{"Namespace", "PVC Name", "Allocated", "Used", "Wasted", "Used(%)", "Wastage(%)", "Attached Pod", "Category"})
+		// namespace-level opt-out annotations
+		var nsAnnotations map[string]string
+		if nsObj, err := Internal.GetNamespace(ns); err == nil {
+			nsAnnotations = nsObj.Annotations
+		}
 
-		var highWastagePVCs, unattachedPVCs, cleanupCandidates []PVCInfo
-		var totalPVCs, totalNamespaces int
-		var totalAllocatedMB, totalUsedMB, totalWastedMB int64
+		pods, err := Internal.ListPods(ns)
+		if err != nil {
+			fmt.Printf("Error listing pods in namespace %s: %v\n", ns, err)
+		}
 
-		for _, ns := range namespaces {
-			pvcs, err := Internal.ListPVCs(ns)
-			if err != nil {
-				fmt.Printf("Error listing PVCs in namespace %s: %v\n", ns, err)
-				continue
-			}
-			if len(pvcs) == 0 {
+		workloads, err := Internal.ListWorkloads(ns)
+		if err != nil {
+			fmt.Printf("Error listing workloads in namespace %s: %v\n", ns, err)
+		}
+
+		nsReport := NamespaceReport{Namespace: ns}
+		for _, pvc := range pvcs {
+			// Pending/Lost PVCs have no capacity; report them in the health section
+			if pvc.Status.Phase != corev1.ClaimBound {
+				health := CheckPVCHealth(pvc, now)
+				unhealthyPVCs = append(unhealthyPVCs, health)
+				switch pvc.Status.Phase {
+				case corev1.ClaimLost:
+					lostPVCs++
+				default:
+					pendingPVCs++
+				}
 				continue
 			}
 
-			nsReport := NamespaceReport{Namespace: ns}
-			for _, pvc := range pvcs {
-				allocated := pvc.Status.Capacity.Storage().Value() / 1024 / 1024 // MB
+			allocated := pvc.Status.Capacity.Storage().Value() / 1024 / 1024 // MB
 
-				podName, _, err := Internal.FindPodAndMountPathForPVC(ns, pvc.Name)
-				var attachedPod string
-				if err != nil || podName == "" {
-					attachedPod = ""
-					unattachedPVCs = append(unattachedPVCs, PVCInfo{Name: pvc.Name, Namespace: ns})
-				} else {
-					attachedPod = podName
-				}
-
-				// Get used size
-				var usedMB int64
-				if attachedPod != "" {
-					usedMB, _ = Internal.GetUsedSizeInMB(clientset, config, ns, pvc.Name)
+			// only running pods count as attached; pending or terminated
+			// pods referencing the claim leave it effectively unattached
+			attachments := Internal.PodAttachmentsForPVC(pods, pvc.Name)
+			attachment := Internal.AttachmentState(attachments)
+			var attachedPod string
+			for _, a := range attachments {
+				if a.Active() {
+					attachedPod = a.PodName
+					break
 				}
+			}
+			if attachedPod == "" && len(attachments) > 0 {
+				// keep a reference to the inactive pod for the report
+				attachedPod = attachments[0].PodName
+			}
+			attached := attachment == Internal.AttachmentRunning
 
-				wastedMB := allocated - usedMB
-				wastagePct := int64(0)
-				usedPct := int64(0)
-				if allocated > 0 {
-					wastagePct = wastedMB * 100 / allocated
-					usedPct = usedMB * 100 / allocated
-				}
+			// Get used size
+			var usedMB int64
+			if attached {
+				usedMB, _ = Internal.GetUsedSizeInMB(clientset, config, ns, pvc.Name)
+			}
 
-				// Assign category
-				category := "Healthy"
+			wastedMB := allocated - usedMB
+			wastagePct := int64(0)
+			usedPct := int64(0)
+			if allocated > 0 {
+				wastagePct = wastedMB * 100 / allocated
+				usedPct = usedMB * 100 / allocated
+			}
 
-				if attachedPod == "" && usedPct <= 5 {
-					category = "Unused"
+			allocatedVal, allocatedUnit := util.FormatSizeMBorGB(allocated)
+			usedVal, usedUnit := util.FormatSizeMBorGB(usedMB)
+			wastedVal, wastedUnit := util.FormatSizeMBorGB(wastedMB)
+
+			pvcInfo := PVCInfo{
+				Name:            pvc.Name,
+				Namespace:       ns,
+				AllocatedMB:     allocated,
+				Allocated:       allocatedVal,
+				AllocatedUnit:   allocatedUnit,
+				UsedMB:          usedMB,
+				Used:            usedVal,
+				UsedUnit:        usedUnit,
+				WastedMB:        wastedMB,
+				Wasted:          wastedVal,
+				WastedUnit:      wastedUnit,
+				WastagePct:      int(wastagePct),
+				UsedPct:         int64(usedPct),
+				AttachedPod:     attachedPod,
+				Attached:        attached,
+				PodPhase:        attachment,
+				CreatedAt:       pvc.CreationTimestamp.Time,
+				UID:             string(pvc.UID),
+				ResourceVersion: pvc.ResourceVersion,
+				RequestedMB:     pvc.Spec.Resources.Requests.Storage().Value() / 1024 / 1024,
+			}
 
-				} else if wastagePct == 100 {
-					category = "Unused"
-				} else if wastagePct <= 10 {
-					category = "Critical"
-				} else if wastagePct >= 70 {
-					category = "Over-provisioned"
+			// lifecycle: PV bind time and last pod activity
+			if pvc.Spec.VolumeName != "" {
+				if pv, err := Internal.GetPV(pvc.Spec.VolumeName); err == nil {
+					pvcInfo.BoundAt = pv.CreationTimestamp.Time
 				}
+			}
+			pvcInfo.LastPodActivity = Internal.LastPodActivityForPVC(pods, pvc.Name)
+			for _, ref := range workloads.ReferencesFor(pvc) {
+				pvcInfo.ReferencedBy = append(pvcInfo.ReferencedBy, ref.String())
+			}
+			if pvc.Spec.StorageClassName != nil {
+				pvcInfo.StorageClass = *pvc.Spec.StorageClassName
+			}
+			sc := storageClasses[pvcInfo.StorageClass]
+			pvcInfo.Provisioner = sc.Provisioner
 
-				allocatedVal, allocatedUnit := util.FormatSizeMBorGB(allocated)
-				usedVal, usedUnit := util.FormatSizeMBorGB(usedMB)
-				wastedVal, wastedUnit := util.FormatSizeMBorGB(wastedMB)
-
-				pvcInfo := PVCInfo{
-					Name:          pvc.Name,
-					Namespace:     ns,
-					AllocatedMB:   allocated,
-					Allocated:     allocatedVal,
-					AllocatedUnit: allocatedUnit,
-					UsedMB:        usedMB,
-					Used:          usedVal,
-					UsedUnit:      usedUnit,
-					WastedMB:      wastedMB,
-					Wasted:        wastedVal,
-					WastedUnit:    wastedUnit,
-					WastagePct:    int(wastagePct),
-					UsedPct:       int64(usedPct),
-					AttachedPod:   attachedPod,
-					Category:      category,
+			if snap, ok := snapshots.ByPVC[pvcKey(ns, pvc.Name)]; ok {
+				pvcInfo.SnapshotCount = snap.Count
+				pvcInfo.SnapshotMB = snap.RestoreSizeMB
+			}
+			if leftover, ok := workloads.ScaleDownLeftoverFor(pvc.Name); ok {
+				pvcInfo.ScaleDownLeftover = leftover.String()
+				pvcInfo.OwnerStatefulSet = leftover.StatefulSet
+			}
+			pvcInfo.AgeDays = int(now.Sub(pvcInfo.CreatedAt).Hours() / 24)
+			pvcInfo.IdleDays = int(now.Sub(pvcInfo.LastActivityAt()).Hours() / 24)
+
+			// growth forecast; the peak also feeds the right-sizing recommendation
+			pvcInfo.DaysUntilFull = -1
+			if samples := history[pvcKey(ns, pvc.Name)]; attached {
+				forecast, ok := FitGrowth(samples, UsageSample{At: now, UsedMB: usedMB}, allocated)
+				pvcInfo.PeakUsedMB = forecast.PeakUsedMB
+				if ok {
+					pvcInfo.Forecasted = true
+					pvcInfo.GrowthMBPerDay = forecast.GrowthMBPerDay
+					pvcInfo.DaysUntilFull = forecast.DaysUntilFull
+					pvcInfo.FullAt = forecast.FullAt
 				}
+			} else {
+				// unmounted volumes do not grow; keep the peak for right-sizing
+				pvcInfo.PeakUsedMB = PeakUsage(samples)
+			}
 
-				nsReport.PVCs = append(nsReport.PVCs, pvcInfo)
-
-				csvRows = append(csvRows, []string{
-					ns,
-					pvc.Name,
-					fmt.Sprintf("%.2f %s", allocatedVal, allocatedUnit),
-					fmt.Sprintf("%.2f %s", usedVal, usedUnit),
-					fmt.Sprintf("%.2f %s", wastedVal, wastedUnit),
-					fmt.Sprintf("%d", usedPct),
-					fmt.Sprintf("%d", wastagePct),
-					attachedPod,
-					category,
-				})
-
-				if wastagePct > 80 {
+			// Assign category
+			category := ClassifyPVC(pvcInfo, now)
+			pvcInfo.Category = category
+
+			// right-sizing recommendation
+			rec := RecommendSize(pvcInfo, SizingRuleFor(sc.Provisioner, sc.Parameters), now)
+			pvcInfo.RecommendedMB = rec.RecommendedMB
+			pvcInfo.ReclaimableMB = rec.ReclaimableMB
+			pvcInfo.Confidence = rec.Confidence
+
+			// monthly cost from OpenCost, falling back to the price catalog
+			var cost PVCCost
+			if pv, ok := openCostPVs[pvc.Spec.VolumeName]; ok {
+				cost = OpenCostToMonthly(pvcInfo, pv, costWindow)
+				pvcInfo.CostSource = CostSourceOpenCost
+			} else if price, ok := catalog.PriceFor(pvcInfo.StorageClass, sc); ok {
+				cost = ComputeCost(pvcInfo, price, sc.Parameters)
+				pvcInfo.CostSource = CostSourceCatalog
+			} else if pricingEnabled {
+				unpricedPVCs++
+			}
+			pvcInfo.MonthlyCost = cost.MonthlyCost
+			pvcInfo.WastedCost = cost.WastedCost
+			pvcInfo.SavingsCost = cost.SavingsCost
+
+			suppression := ResolveSuppression(pvc, nsAnnotations, allocated, suppressions, now)
+			pvcInfo.Suppressed = suppression.Suppressed
+			pvcInfo.SuppressionExpired = suppression.Expired
+			pvcInfo.SuppressionReason = suppression.Reason
+			pvcInfo.SuppressionOwner = suppression.Owner
+			pvcInfo.SuppressionExpires = suppression.Expires
+
+			nsReport.PVCs = append(nsReport.PVCs, pvcInfo)
+
+			csvRows = append(csvRows, []string{
+				ns,
+				pvc.Name,
+				fmt.Sprintf("%.2f %s", allocatedVal, allocatedUnit),
+				fmt.Sprintf("%.2f %s", usedVal, usedUnit),
+				fmt.Sprintf("%.2f %s", wastedVal, wastedUnit),
+				fmt.Sprintf("%d", usedPct),
+				fmt.Sprintf("%d", wastagePct),
+				attachedPod,
+				attachment,
+				strings.Join(pvcInfo.ReferencedBy, "; "),
+				category,
+				FormatAge(now.Sub(pvcInfo.CreatedAt)),
+				FormatAge(now.Sub(pvcInfo.LastActivityAt())),
+				fmt.Sprintf("%.1f MB", pvcInfo.GrowthMBPerDay),
+				FormatDaysUntilFull(pvcInfo),
+				formatDate(pvcInfo.FullAt),
+				fmt.Sprintf("%d MB", pvcInfo.RecommendedMB),
+				fmt.Sprintf("%d MB", pvcInfo.ReclaimableMB),
+				pvcInfo.Confidence,
+				fmt.Sprintf("%.2f", pvcInfo.MonthlyCost),
+				fmt.Sprintf("%.2f", pvcInfo.WastedCost),
+				fmt.Sprintf("%.2f", pvcInfo.SavingsCost),
+				pvcInfo.CostSource,
+				fmt.Sprintf("%d", pvcInfo.SnapshotCount),
+				fmt.Sprintf("%d MB", pvcInfo.SnapshotMB),
+				fmt.Sprintf("%t", pvcInfo.Suppressed),
+				pvcInfo.SuppressionReason,
+				pvcInfo.SuppressionOwner,
+				pvcInfo.SuppressionExpires,
+			})
+
+			// suppressed findings are reported separately
+			if pvcInfo.Suppressed {
+				suppressedPVCs = append(suppressedPVCs, pvcInfo)
+			} else {
+				if pvcInfo.SuppressionExpired {
+					expiredSuppressed = append(expiredSuppressed, pvcInfo)
+				}
+				if !attached {
+					unattachedPVCs = append(unattachedPVCs, pvcInfo)
+				}
+				switch category {
+				case CategoryFillingFast:
+					fillingFastPVCs = append(fillingFastPVCs, pvcInfo)
+				case CategoryDormant:
+					dormantPVCs = append(dormantPVCs, pvcInfo)
+				case CategoryOrphaned, CategoryAbandoned:
+					orphanedPVCs = append(orphanedPVCs, pvcInfo)
+				case CategoryScaleDownLeft:
+					scaleDownLeftovers = append(scaleDownLeftovers, pvcInfo)
+				}
+				if wastagePct > 80 && IsFlaggable(category) {
 					highWastagePVCs = append(highWastagePVCs, pvcInfo)
 					cleanupCandidates = append(cleanupCandidates, pvcInfo)
 				}
+			}
 
-				totalAllocatedMB += allocated
-				totalUsedMB += usedMB
-				totalWastedMB += wastedMB
-				totalPVCs++
+			totalAllocatedMB += allocated
+			totalUsedMB += usedMB
+			totalWastedMB += wastedMB
+			totalMonthlyCost += pvcInfo.MonthlyCost
+			totalWastedCost += pvcInfo.WastedCost
+			if countsTowardSavings(pvcInfo) {
+				totalReclaimableMB += pvcInfo.ReclaimableMB
+				totalSavingsCost += pvcInfo.SavingsCost
+			} else if !pvcInfo.Suppressed && pvcInfo.Category != CategoryDormant {
+				lowConfidenceMB += pvcInfo.ReclaimableMB
 			}
+			totalPVCs++
+		}
+
+		if len(nsReport.PVCs) > 0 {
+			namespaceReports = append(namespaceReports, nsReport)
+			totalNamespaces++
+		}
+	}
+
+	// Generate cluster report data
+	totalAllocatedGB := float64(totalAllocatedMB) / 1024
+	totalUsedGB := float64(totalUsedMB) / 1024
+	totalWastedGB := float64(totalWastedMB) / 1024
+	totalWastagePct := int64(0)
+	if totalAllocatedMB > 0 {
+		totalWastagePct = totalWastedMB * 100 / totalAllocatedMB
+	}
+
+	clusterReport := ClusterReport{
+		ClusterName:        clusterName,
+		GeneratedAt:        time.Now().Format("2006-01-02 15:04:05"),
+		TotalNamespaces:    totalNamespaces,
+		Namespaces:         namespaces,
+		AllNamespaces:      allNamespaces,
+		TotalPVCs:          totalPVCs,
+		PVCsWithWastage:    len(highWastagePVCs),
+		PVCsWithoutWastage: totalPVCs - len(highWastagePVCs),
+		TotalAllocatedGB:   totalAllocatedGB,
+		TotalUsedGB:        totalUsedGB,
+		TotalWastedGB:      totalWastedGB,
+		TotalWastagePct:    totalWastagePct,
+		NamespaceReports:   namespaceReports,
+		HighWastagePVCs:    highWastagePVCs,
+		UnattachedPVCs:     unattachedPVCs,
+		CleanupCandidates:  cleanupCandidates,
+		FillingFastPVCs:    fillingFastPVCs,
+		DormantPVCs:        dormantPVCs,
+		OrphanedPVCs:       orphanedPVCs,
+		ScaleDownLeftovers: scaleDownLeftovers,
+		UnhealthyPVCs:      unhealthyPVCs,
+		TotalReclaimableMB: totalReclaimableMB,
+		LowConfidenceMB:    lowConfidenceMB,
+		TotalMonthlyCost:   totalMonthlyCost,
+		TotalWastedCost:    totalWastedCost,
+		TotalSavingsCost:   totalSavingsCost,
+		UnpricedPVCs:       unpricedPVCs,
+		SnapshotSummaries:  snapshots.Summaries(),
+		OrphanedSnapshots:  snapshots.Orphaned,
+		TotalSnapshotMB:    snapshots.TotalMB,
+		StaleSnapshots:     snapshots.Stale,
+		PendingPVCs:        pendingPVCs,
+		LostPVCs:           lostPVCs,
+		SuppressedPVCs:     suppressedPVCs,
+		ExpiredSuppressed:  expiredSuppressed,
+	}
+
+	if catalog != nil {
+		clusterReport.Currency = catalog.Currency
+	} else if pricingEnabled {
+		clusterReport.Currency = defaultCurrency
+	}
+
+	// PVs are cluster-scoped, audit them with -A
+	if allNamespaces {
+		pvInfos, err := CollectPVInfos()
+		if err != nil {
+			fmt.Printf("Error auditing PersistentVolumes: %v\n", err)
+		} else {
+			clusterReport.PVFindings, clusterReport.PVFindingsMB = FlaggedPVs(pvInfos)
+		}
+	}
+
+	return clusterReport, csvRows, nil
+}
 
-			if len(nsReport.PVCs) > 0 {
-				namespaceReports = append(namespaceReports, nsReport)
-				totalNamespaces++
+var (
+	pushgatewayServer string
+	suppressionsFile  string
+	baselineFile      string
+	failOnNew         bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit PVCs and generate wastage report",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		// Determine namespaces
+		var namespaces []string
+		if allNamespaces {
+			nsList, err := Internal.ListNamespaces()
+			if err != nil {
+				return err
+			}
+			namespaces = nsList
+		} else {
+			namespaces = []string{namespace}
+		}
+
+		var baseline *ClusterReport
+		if baselineFile != "" {
+			b, err := LoadJSONReport(baselineFile)
+			if err != nil {
+				return err
 			}
+			baseline = &b
+		}
+
+		clusterReport, csvRows, err := RunAudit(namespaces)
+		if err != nil {
+			return err
 		}
 
 		// Write CSV by default
 		os.MkdirAll("reports", 0755)
-		csvFile := filepath.Join("reports", fmt.Sprintf("pvc-wastage-report-%s.csv", time.Now().Format("20060102-150405")))
+		reportStamp := time.Now().Format("20060102-150405")
+		csvFile := filepath.Join("reports", fmt.Sprintf("pvc-wastage-report-%s.csv", reportStamp))
 		file, err := os.Create(csvFile)
 		if err != nil {
 			return err
@@ -261,31 +613,17 @@ var auditCmd = &cobra.Command{
 		defer writer.Flush()
 		writer.WriteAll(csvRows)
 
-		// Generate cluster report data
-		totalAllocatedGB := float64(totalAllocatedMB) / 1024
-		totalUsedGB := float64(totalUsedMB) / 1024
-		totalWastedGB := float64(totalWastedMB) / 1024
-		totalWastagePct := int64(0)
-		if totalAllocatedMB > 0 {
-			totalWastagePct = totalWastedMB * 100 / totalAllocatedMB
+		clusterReport.CSVFilePath = csvFile
+		clusterReport.JSONFilePath = filepath.Join("reports", fmt.Sprintf("pvc-wastage-report-%s.json", reportStamp))
+
+		if baseline != nil {
+			cmp := CompareWithBaseline(clusterReport, *baseline, baselineFile)
+			clusterReport.Baseline = &cmp
 		}
 
-		clusterReport := ClusterReport{
-			ClusterName:        clusterName,
-			GeneratedAt:        time.Now().Format("2006-01-02 15:04:05"),
-			TotalNamespaces:    totalNamespaces,
-			TotalPVCs:          totalPVCs,
-			PVCsWithWastage:    len(highWastagePVCs),
-			PVCsWithoutWastage: totalPVCs - len(highWastagePVCs),
-			TotalAllocatedGB:   totalAllocatedGB,
-			TotalUsedGB:        totalUsedGB,
-			TotalWastedGB:      totalWastedGB,
-			TotalWastagePct:    totalWastagePct,
-			NamespaceReports:   namespaceReports,
-			HighWastagePVCs:    highWastagePVCs,
-			UnattachedPVCs:     unattachedPVCs,
-			CleanupCandidates:  cleanupCandidates,
-			CSVFilePath:        csvFile,
+		// JSON report doubles as the baseline for future runs
+		if err := SaveJSONReport(clusterReport.JSONFilePath, clusterReport); err != nil {
+			fmt.Printf("❌ Error writing JSON report: %v\n", err)
 		}
 
 		// Output
@@ -307,6 +645,10 @@ var auditCmd = &cobra.Command{
 			PrintClusterReportCLI(clusterReport)
 		}
 
+		if failOnNew && clusterReport.Baseline != nil && clusterReport.Baseline.New > 0 {
+			return fmt.Errorf("%d new finding(s) compared to baseline %s", clusterReport.Baseline.New, baselineFile)
+		}
+
 		return nil
 	},
 }
@@ -316,4 +658,24 @@ func init() {
 	auditCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
 	auditCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Audit all namespaces")
 	auditCmd.Flags().StringVarP(&pushgatewayServer, "server-ip", "s", "", "Pushgateway server IP (e.g., http://localhost:9091)")
+	auditCmd.Flags().StringVar(&baselineFile, "baseline", "", "Saved JSON report to compare against (flags new, resolved, worsened and unchanged findings)")
+	auditCmd.Flags().BoolVar(&failOnNew, "fail-on-new", false, "Exit with an error when --baseline finds new findings (for CI)")
+	addAuditFlags(auditCmd)
+}
+
+// addAuditFlags registers the flags that drive RunAudit on a command
+func addAuditFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&gracePeriod, "grace-period", 72*time.Hour, "Do not flag PVCs younger than this as wasteful")
+	cmd.Flags().DurationVar(&abandonAfter, "abandon-after", 30*24*time.Hour, "Unattached PVCs without pod activity for this long are reported as Abandoned")
+	cmd.Flags().DurationVar(&snapshotRetention, "snapshot-retention", 30*24*time.Hour, "Flag VolumeSnapshots older than this")
+	cmd.Flags().StringVar(&historyDir, "history-dir", "reports", "Directory of saved JSON reports used as usage history for growth forecasts (empty disables)")
+	cmd.Flags().StringVar(&prometheusURL, "prometheus-url", "", "Prometheus with kubelet volume stats, used as usage history for growth forecasts")
+	cmd.Flags().DurationVar(&historyWindow, "history-window", 14*24*time.Hour, "How far back usage history is considered")
+	cmd.Flags().IntVar(&fillingFastAfter, "filling-fast", 14, "Flag PVCs projected to be full within this many days as Filling fast")
+	cmd.Flags().Float64Var(&headroomPct, "headroom", 20, "Right-sizing headroom on top of used/peak space (%)")
+	cmd.Flags().Float64Var(&growthPct, "growth", 10, "Right-sizing growth allowance (%)")
+	cmd.Flags().StringVar(&pricingFile, "pricing", "", "Pricing file (YAML/JSON) with price per GiB-month per storage class or provisioner")
+	cmd.Flags().StringVar(&openCostURL, "opencost-url", "", "OpenCost-compatible allocation API (e.g. http://opencost.opencost:9003) for real PV costs")
+	cmd.Flags().StringVar(&openCostWindow, "opencost-window", "7d", "OpenCost window the monthly cost is extrapolated from")
+	cmd.Flags().StringVar(&suppressionsFile, "suppressions", "", "Suppressions file (YAML/JSON) with reason, owner and expiry per PVC")
 }
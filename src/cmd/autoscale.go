@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	autoscaleDryRun  bool
+	autoscaleOnce    bool
+	autoscaleTimeout time.Duration
+)
+
+// Event reasons recorded on PVCs by the autoscale controller
+const (
+	EventAutoExpanded          = "SpacioAutoExpanded"
+	EventAutoExpandFailed      = "SpacioAutoExpandFailed"
+	EventAutoExpandAtMax       = "SpacioAutoExpandAtMaxSize"
+	EventFilesystemNotExpanded = "SpacioFilesystemNotExpanded"
+)
+
+// ExpandDecision is the outcome of checking a PVC against its auto-expand settings
+type ExpandDecision struct {
+	UsedPct int64
+	Current resource.Quantity
+	Target  resource.Quantity
+	Expand  bool
+	AtMax   bool // over the threshold but already at the maximum size
+	Reason  string
+}
+
+// DecideExpansion decides whether a PVC crossed its threshold and how far to
+// grow it: by the increment, capped at the maximum size, unless the volume is
+// still cooling down from its last expansion
+func DecideExpansion(pvc corev1.PersistentVolumeClaim, settings ExpandSettings, usedBytes int64, now time.Time) ExpandDecision {
+	d := ExpandDecision{Current: CurrentSize(pvc)}
+	capacity := pvc.Status.Capacity.Storage()
+	if capacity.IsZero() {
+		d.Reason = "no capacity reported"
+		return d
+	}
+	d.UsedPct = usedBytes * 100 / capacity.Value()
+	if d.UsedPct < int64(settings.Threshold) {
+		d.Reason = fmt.Sprintf("%d%% used, below the %d%% threshold", d.UsedPct, settings.Threshold)
+		return d
+	}
+	d = decideGrowth(pvc, settings, now, d, fmt.Sprintf("%d%% used", d.UsedPct))
+	if d.Expand {
+		d.Reason = fmt.Sprintf("%d%% used (threshold %d%%)", d.UsedPct, settings.Threshold)
+	}
+	return d
+}
+
+// decideGrowth sizes the expansion of a PVC that needs to grow, for the
+// reason given by trigger: by the increment, capped at the maximum size,
+// unless the volume is still cooling down from its last expansion
+func decideGrowth(pvc corev1.PersistentVolumeClaim, settings ExpandSettings, now time.Time, d ExpandDecision, trigger string) ExpandDecision {
+	if last, err := time.Parse(time.RFC3339, pvc.Annotations[AnnotationLastAutoExpand]); err == nil {
+		if until := last.Add(settings.Cooldown); now.Before(until) {
+			d.Reason = fmt.Sprintf("%s, cooling down until %s", trigger, until.Format(time.RFC3339))
+			return d
+		}
+	}
+
+	target, err := ParseResizeTarget(d.Current, "", settings.Increment)
+	if err != nil {
+		d.Reason = err.Error()
+		return d
+	}
+	if !settings.MaxSize.IsZero() && target.Cmp(settings.MaxSize) > 0 {
+		target = settings.MaxSize.DeepCopy()
+	}
+	if target.Cmp(d.Current) <= 0 {
+		d.AtMax = true
+		d.Reason = fmt.Sprintf("%s, already at the maximum size %s", trigger, settings.MaxSize.String())
+		return d
+	}
+	d.Target = target
+	d.Expand = true
+	d.Reason = trigger
+	return d
+}
+
+// autoscaler is the state of the autoscale controller across passes
+type autoscaler struct {
+	policy   AutoExpandPolicy
+	guard    *Guard        // maintenance windows and change budgets, never forced
+	hooks    *Hooks        // run around each expansion
+	slots    chan struct{} // caps the expansions running at once
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	inFlight map[string]bool
+	lastNote map[string]string // last reported state per PVC, so that only changes are logged
+}
+
+func newAutoscaler(policy AutoExpandPolicy, guard *Guard, hooks *Hooks) *autoscaler {
+	return &autoscaler{
+		policy:   policy,
+		guard:    guard,
+		hooks:    hooks,
+		slots:    make(chan struct{}, policy.ConcurrencyOrDefault()),
+		inFlight: map[string]bool{},
+		lastNote: map[string]string{},
+	}
+}
+
+func (a *autoscaler) logf(format string, args ...interface{}) {
+	fmt.Printf("%s "+format+"\n", append([]interface{}{time.Now().Format(time.RFC3339)}, args...)...)
+}
+
+// note logs the state of a PVC when it differs from the previous pass and
+// reports whether it changed
+func (a *autoscaler) note(key, msg string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lastNote[key] == msg {
+		return false
+	}
+	a.lastNote[key] = msg
+	a.logf("%s: %s", key, msg)
+	return true
+}
+
+// usageSource returns the used bytes of a PVC, from Prometheus when
+// --prometheus-url is set, otherwise measured with df in a mounting pod
+func (a *autoscaler) usageSource() (func(pvc corev1.PersistentVolumeClaim) (int64, error), error) {
+	if prometheusURL != "" {
+		usage, err := internal.QueryVolumeUsage(nil, prometheusURL)
+		if err != nil {
+			return nil, err
+		}
+		return func(pvc corev1.PersistentVolumeClaim) (int64, error) {
+			used, ok := usage[pvc.Namespace+"/"+pvc.Name]
+			if !ok {
+				return 0, fmt.Errorf("no kubelet volume stats in Prometheus")
+			}
+			return int64(used), nil
+		}, nil
+	}
+	return func(pvc corev1.PersistentVolumeClaim) (int64, error) {
+		return measureUsedBytes(pvc.Namespace, pvc.Name)
+	}, nil
+}
+
+// pass checks every bound PVC of the namespaces once and starts the expansions due
+func (a *autoscaler) pass(namespaces []string) error {
+	usageOf, err := a.usageSource()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, ns := range namespaces {
+		pvcs, err := internal.ListPVCs(ns)
+		if err != nil {
+			a.logf("⚠️  listing PVCs in %s: %v", ns, err)
+			continue
+		}
+		for _, pvc := range pvcs {
+			key := pvc.Namespace + "/" + pvc.Name
+			if pvc.Status.Phase != corev1.ClaimBound {
+				continue
+			}
+			settings, err := a.policy.ExpandSettingsFor(pvc)
+			if err != nil {
+				a.note(key+" annotations", err.Error())
+			}
+			if !settings.Enabled {
+				continue
+			}
+			a.mu.Lock()
+			busy := a.inFlight[key]
+			a.mu.Unlock()
+			if busy {
+				continue
+			}
+
+			used, err := usageOf(pvc)
+			if err != nil {
+				a.note(key, fmt.Sprintf("usage not measured: %v", err))
+				continue
+			}
+			d := DecideExpansion(pvc, settings, used, now)
+			if !d.Expand {
+				if a.note(key, d.Reason) && d.AtMax {
+					internal.RecordEvent(internal.PVCReference(pvc), corev1.EventTypeWarning, EventAutoExpandAtMax, d.Reason)
+				}
+				continue
+			}
+			if autoscaleDryRun {
+				a.note(key, fmt.Sprintf("%s, would expand %s → %s", d.Reason, d.Current.String(), d.Target.String()))
+				continue
+			}
+
+			select {
+			case a.slots <- struct{}{}:
+			default:
+				a.note(key, fmt.Sprintf("%s, deferred: %d expansions already running", d.Reason, cap(a.slots)))
+				continue
+			}
+			delta := d.Target.DeepCopy()
+			delta.Sub(d.Current)
+			admission, err := a.guard.Admit(Change{Namespace: pvc.Namespace, Name: pvc.Name, Action: ActionExpand, Bytes: delta.Value()}, now)
+			if err != nil {
+				<-a.slots
+				a.note(key, fmt.Sprintf("%s, deferred: %v", d.Reason, err))
+				continue
+			}
+			a.mu.Lock()
+			a.inFlight[key] = true
+			a.mu.Unlock()
+			a.wg.Add(1)
+			go a.expand(pvc, d, admission)
+		}
+	}
+	return nil
+}
+
+// expand runs a single expansion and records its outcome as an Event on the PVC
+func (a *autoscaler) expand(pvc corev1.PersistentVolumeClaim, d ExpandDecision, admission Admission) {
+	key := pvc.Namespace + "/" + pvc.Name
+	defer func() {
+		a.mu.Lock()
+		delete(a.inFlight, key)
+		delete(a.lastNote, key)
+		a.mu.Unlock()
+		<-a.slots
+		a.wg.Done()
+	}()
+
+	a.logf("📏 %s: %s, expanding %s → %s", key, d.Reason, d.Current.String(), d.Target.String())
+	result, err := autoExpand(pvc, d, admission, a.guard, a.hooks, autoscaleTimeout, func(format string, args ...interface{}) {
+		a.logf("  ↳ %s: "+format, append([]interface{}{key}, args...)...)
+	})
+	if err != nil {
+		a.logf("❌ %s: %v", key, err)
+		return
+	}
+	a.logf("✅ %s: %s: expanded %s → %s (%s)", key, d.Reason, d.Current.String(), d.Target.String(), result.Phase)
+	if result.Filesystem.Status == FilesystemPending {
+		a.logf("⏳ %s: %s", key, result.Filesystem.Message)
+	}
+}
+
+// autoExpand runs an admitted automatic expansion within the hooks of the
+// PVC, confirms its reservation in the change ledger once the request was
+// made (even if the expansion then failed) or releases it, and records
+// its outcome as Events on the PVC
+func autoExpand(pvc corev1.PersistentVolumeClaim, d ExpandDecision, admission Admission, guard *Guard, hooks *Hooks, timeout time.Duration, logf Logf) (ResizeResult, error) {
+	// providers count the cooldown from the modification request, so it is
+	// recorded before the request is made, and restored when none was made
+	var previous interface{}
+	if value, ok := pvc.Annotations[AnnotationLastAutoExpand]; ok {
+		previous = value
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339)
+	if err := internal.PatchPVCAnnotations(pvc.Namespace, pvc.Name, map[string]interface{}{AnnotationLastAutoExpand: stamp}); err != nil {
+		releaseAdmission(guard, admission, logf)
+		return ResizeResult{}, fmt.Errorf("recording %s: %v", AnnotationLastAutoExpand, err)
+	}
+
+	var result ResizeResult
+	payload := HookPayload{Action: ActionExpand, From: d.Current.String(), To: d.Target.String()}
+	err := hooks.Run(pvc, payload, logf, func() error {
+		var err error
+		result, err = ExpandPVC(pvc.Namespace, pvc.Name, d.Target, timeout, logf)
+		return err
+	})
+	ref := internal.PVCReference(pvc)
+	if err != nil {
+		msg := fmt.Sprintf("%s: expanding %s → %s failed (%s): %v", d.Reason, d.Current.String(), d.Target.String(), result.Phase, err)
+		internal.RecordEvent(ref, corev1.EventTypeWarning, EventAutoExpandFailed, msg)
+		// a request that was made counts against the budget and the cooldown
+		if expansionRequested(pvc, d.Target) {
+			if err := guard.Record(admission, time.Now()); err != nil {
+				logf("⚠️  recording the change in the ledger: %v", err)
+			}
+			return result, errors.New(msg)
+		}
+		releaseAdmission(guard, admission, logf)
+		if err := internal.PatchPVCAnnotations(pvc.Namespace, pvc.Name, map[string]interface{}{AnnotationLastAutoExpand: previous}); err != nil {
+			logf("⚠️  restoring %s: %v", AnnotationLastAutoExpand, err)
+		}
+		return result, errors.New(msg)
+	}
+	if err := guard.Record(admission, time.Now()); err != nil {
+		logf("⚠️  recording the change in the ledger: %v", err)
+	}
+	msg := fmt.Sprintf("%s: expanded %s → %s (%s)", d.Reason, d.Current.String(), d.Target.String(), result.Phase)
+	internal.RecordEvent(ref, corev1.EventTypeNormal, EventAutoExpanded, msg)
+
+	if err := settleFilesystem(&result, resizeRestart, timeout, logf); err != nil {
+		internal.RecordEvent(ref, corev1.EventTypeWarning, EventFilesystemNotExpanded, err.Error())
+		return result, err
+	}
+	if result.Filesystem.Status == FilesystemPending {
+		internal.RecordEvent(ref, corev1.EventTypeWarning, EventFilesystemNotExpanded, result.Filesystem.Message)
+	}
+	return result, nil
+}
+
+// expansionRequested reports whether the storage request of a PVC was raised
+// to the target; when the PVC cannot be read the request is assumed made
+func expansionRequested(pvc corev1.PersistentVolumeClaim, target resource.Quantity) bool {
+	current, err := internal.GetPVC(pvc.Namespace, pvc.Name)
+	if err != nil {
+		return true
+	}
+	return current.Spec.Resources.Requests.Storage().Cmp(target) >= 0
+}
+
+var autoscaleCmd = &cobra.Command{
+	Use:   "autoscale",
+	Short: "Run a controller that expands PVCs crossing their usage threshold, governed by annotations and a policy file",
+	Example: `  spacio autoscale -A --policy policy.yaml --prometheus-url http://prometheus:9090
+  spacio autoscale -n db --dry-run --once`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := LoadPolicy(policyFile)
+		if err != nil {
+			return err
+		}
+		guard, err := NewGuard(policy.Governance, false, "")
+		if err != nil {
+			return err
+		}
+		a := newAutoscaler(policy.AutoExpand, guard, NewHooks(policy.Hooks))
+		interval := policy.AutoExpand.IntervalOrDefault()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		source := "du in mounting pods"
+		if prometheusURL != "" {
+			source = prometheusURL
+		}
+		a.logf("🤖 autoscale started: every %s, %d concurrent expansion(s), usage from %s", interval, cap(a.slots), source)
+	loop:
+		for {
+			namespaces := []string{namespace}
+			if allNamespaces {
+				if namespaces, err = internal.ListNamespaces(); err != nil {
+					a.logf("⚠️  listing namespaces: %v", err)
+				}
+			}
+			if err := a.pass(namespaces); err != nil {
+				a.logf("⚠️  %v", err)
+			}
+			if autoscaleOnce {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-time.After(interval):
+			}
+		}
+		a.logf("waiting for running expansions to finish")
+		a.wg.Wait()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(autoscaleCmd)
+	autoscaleCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	autoscaleCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Watch all namespaces")
+	autoscaleCmd.Flags().StringVar(&policyFile, "policy", "", "Policy file (YAML or JSON) with auto-expand rules, maintenance windows, change budgets and hooks")
+	autoscaleCmd.Flags().StringVar(&prometheusURL, "prometheus-url", "", "Read usage from kubelet volume stats in Prometheus instead of running du in pods")
+	autoscaleCmd.Flags().BoolVar(&autoscaleDryRun, "dry-run", false, "Only log the expansions that would be made")
+	autoscaleCmd.Flags().BoolVar(&autoscaleOnce, "once", false, "Run a single pass and exit")
+	autoscaleCmd.Flags().DurationVar(&autoscaleTimeout, "timeout", 10*time.Minute, "How long to follow each expansion")
+	autoscaleCmd.Flags().BoolVar(&resizeRestart, "restart-pod", false, "Restart pods whose filesystem did not grow after an expansion (CSI drivers expanding offline only)")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDecideExpansion(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	settings := ExpandSettings{Enabled: true, Threshold: 80, Increment: "50%", MaxSize: resource.MustParse("20Gi"), Cooldown: time.Hour}
+	pvc := func(size, lastExpand string) corev1.PersistentVolumeClaim {
+		p := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "db"}}
+		p.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+		p.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+		if lastExpand != "" {
+			p.Annotations = map[string]string{AnnotationLastAutoExpand: lastExpand}
+		}
+		return p
+	}
+	const gi = int64(1024 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		pvc      corev1.PersistentVolumeClaim
+		settings ExpandSettings
+		used     int64
+		expand   bool
+		atMax    bool
+		target   string
+	}{
+		{"below threshold", pvc("10Gi", ""), settings, 7 * gi, false, false, ""},
+		{"over threshold", pvc("10Gi", ""), settings, 9 * gi, true, false, "15Gi"},
+		{"capped at the maximum", pvc("16Gi", ""), settings, 15 * gi, true, false, "20Gi"},
+		{"already at the maximum", pvc("20Gi", ""), settings, 19 * gi, false, true, ""},
+		{"no maximum", pvc("20Gi", ""), ExpandSettings{Threshold: 80, Increment: "10Gi"}, 19 * gi, true, false, "30Gi"},
+		{"cooling down", pvc("10Gi", now.Add(-30*time.Minute).Format(time.RFC3339)), settings, 9 * gi, false, false, ""},
+		{"cooldown over", pvc("10Gi", now.Add(-2*time.Hour).Format(time.RFC3339)), settings, 9 * gi, true, false, "15Gi"},
+		{"invalid increment", pvc("10Gi", ""), ExpandSettings{Threshold: 80, Increment: "lots"}, 9 * gi, false, false, ""},
+		{"no capacity", corev1.PersistentVolumeClaim{}, settings, 9 * gi, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DecideExpansion(tt.pvc, tt.settings, tt.used, now)
+			if d.Expand != tt.expand || d.AtMax != tt.atMax {
+				t.Fatalf("DecideExpansion() = expand %v, at max %v (%s), want expand %v, at max %v", d.Expand, d.AtMax, d.Reason, tt.expand, tt.atMax)
+			}
+			if d.Reason == "" {
+				t.Error("DecideExpansion() gave no reason")
+			}
+			if tt.target != "" {
+				if want := resource.MustParse(tt.target); d.Target.Cmp(want) != 0 {
+					t.Errorf("DecideExpansion() target = %s, want %s", d.Target.String(), tt.target)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Finding statuses when comparing an audit against a baseline report
+const (
+	FindingNew       = "new"
+	FindingResolved  = "resolved"
+	FindingWorsened  = "worsened"
+	FindingImproved  = "improved"
+	FindingUnchanged = "unchanged"
+)
+
+// categorySeverity ranks finding categories, most urgent first, so that a
+// change of category can be told apart as worse or better
+var categorySeverity = map[string]int{
+	CategoryCritical:        6,
+	CategoryFillingFast:     5,
+	CategoryAbandoned:       4,
+	CategoryOrphaned:        3,
+	CategoryScaleDownLeft:   3,
+	CategoryIdle:            2,
+	CategoryDormant:         2,
+	CategoryOverProvisioned: 1,
+}
+
+// FindingDiff is a single PVC finding compared against the baseline
+type FindingDiff struct {
+	Namespace        string
+	Name             string
+	Status           string // new, resolved, worsened, improved or unchanged
+	Category         string // current category (baseline category for resolved findings)
+	BaselineCategory string
+	WastedMB         int64
+	BaselineWastedMB int64
+}
+
+// BaselineComparison summarises how the current audit differs from a saved report
+type BaselineComparison struct {
+	BaselineFile        string
+	BaselineGeneratedAt string
+	Findings            []FindingDiff
+	New                 int
+	Resolved            int
+	Worsened            int
+	Improved            int
+	Unchanged           int
+	NetWasteDeltaMB     int64 // change in total wasted space across the PVCs of the compared namespaces
+	FindingWasteDeltaMB int64 // change in wasted space of flagged PVCs only
+}
+
+// SaveJSONReport writes the cluster report as JSON so it can be used as a future baseline
+func SaveJSONReport(file string, clusterReport ClusterReport) error {
+	data, err := json.MarshalIndent(clusterReport, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// LoadJSONReport reads a report previously written by SaveJSONReport
+func LoadJSONReport(file string) (ClusterReport, error) {
+	var clusterReport ClusterReport
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return clusterReport, fmt.Errorf("reading baseline report: %v", err)
+	}
+	if err := json.Unmarshal(data, &clusterReport); err != nil {
+		return clusterReport, fmt.Errorf("parsing baseline report %s: %v", file, err)
+	}
+	return clusterReport, nil
+}
+
+// isFinding reports whether a PVC counts as an actionable finding
+func isFinding(pvc PVCInfo) bool {
+	return !pvc.Suppressed && pvc.Category != CategoryHealthy && pvc.Category != CategoryNewlyProvisioned && pvc.Category != ""
+}
+
+func pvcKey(ns, name string) string {
+	return ns + "/" + name
+}
+
+// indexPVCs indexes the PVCs of a report by namespace/name, keeping only the
+// namespaces in scope (all of them when scope is nil)
+func indexPVCs(clusterReport ClusterReport, scope map[string]bool) map[string]PVCInfo {
+	index := map[string]PVCInfo{}
+	for _, nsReport := range clusterReport.NamespaceReports {
+		if scope != nil && !scope[nsReport.Namespace] {
+			continue
+		}
+		for _, pvc := range nsReport.PVCs {
+			index[pvcKey(nsReport.Namespace, pvc.Name)] = pvc
+		}
+	}
+	return index
+}
+
+// comparedNamespaces returns the namespaces both audits covered, nil when
+// both covered the whole cluster. Reports written before the scope was
+// recorded count as cluster-wide.
+func comparedNamespaces(current, baseline ClusterReport) map[string]bool {
+	var scope map[string]bool
+	for _, r := range []ClusterReport{current, baseline} {
+		if r.AllNamespaces || len(r.Namespaces) == 0 {
+			continue
+		}
+		covered := map[string]bool{}
+		for _, ns := range r.Namespaces {
+			if scope == nil || scope[ns] {
+				covered[ns] = true
+			}
+		}
+		scope = covered
+	}
+	return scope
+}
+
+// compareFinding tells whether a finding got worse, better or stayed the
+// same: by the severity of its category, then by its wasted space
+func compareFinding(pvc, old PVCInfo) string {
+	now, before := categorySeverity[pvc.Category], categorySeverity[old.Category]
+	switch {
+	case now > before, now == before && pvc.WastedMB > old.WastedMB:
+		return FindingWorsened
+	case now < before, pvc.WastedMB < old.WastedMB:
+		return FindingImproved
+	}
+	return FindingUnchanged
+}
+
+// CompareWithBaseline classifies every current and baseline finding as new,
+// resolved, worsened, improved or unchanged. Only the namespaces both audits
+// covered are compared, so that a namespaced run against a cluster-wide
+// baseline does not report the other namespaces as resolved.
+func CompareWithBaseline(current, baseline ClusterReport, baselineFile string) BaselineComparison {
+	cmp := BaselineComparison{
+		BaselineFile:        baselineFile,
+		BaselineGeneratedAt: baseline.GeneratedAt,
+	}
+
+	scope := comparedNamespaces(current, baseline)
+	currentPVCs := indexPVCs(current, scope)
+	baselinePVCs := indexPVCs(baseline, scope)
+
+	var currentWasted, baselineWasted int64
+	for key, pvc := range currentPVCs {
+		currentWasted += pvc.WastedMB
+
+		if !isFinding(pvc) {
+			continue
+		}
+		diff := FindingDiff{
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+			Category:  pvc.Category,
+			WastedMB:  pvc.WastedMB,
+		}
+		old, existed := baselinePVCs[key]
+		switch {
+		case !existed || !isFinding(old):
+			diff.Status = FindingNew
+			cmp.New++
+			cmp.FindingWasteDeltaMB += pvc.WastedMB
+		default:
+			diff.Status = compareFinding(pvc, old)
+			diff.BaselineCategory = old.Category
+			diff.BaselineWastedMB = old.WastedMB
+			switch diff.Status {
+			case FindingWorsened:
+				cmp.Worsened++
+			case FindingImproved:
+				cmp.Improved++
+			default:
+				cmp.Unchanged++
+			}
+			cmp.FindingWasteDeltaMB += pvc.WastedMB - old.WastedMB
+		}
+		cmp.Findings = append(cmp.Findings, diff)
+	}
+
+	for key, old := range baselinePVCs {
+		baselineWasted += old.WastedMB
+
+		if !isFinding(old) {
+			continue
+		}
+		if pvc, ok := currentPVCs[key]; ok && isFinding(pvc) {
+			continue
+		}
+		diff := FindingDiff{
+			Namespace:        old.Namespace,
+			Name:             old.Name,
+			Status:           FindingResolved,
+			Category:         old.Category,
+			BaselineCategory: old.Category,
+			BaselineWastedMB: old.WastedMB,
+		}
+		if pvc, ok := currentPVCs[key]; ok {
+			diff.Category = pvc.Category
+			diff.WastedMB = pvc.WastedMB
+		}
+		cmp.Resolved++
+		cmp.FindingWasteDeltaMB -= old.WastedMB
+		cmp.Findings = append(cmp.Findings, diff)
+	}
+	cmp.NetWasteDeltaMB = currentWasted - baselineWasted
+
+	order := map[string]int{FindingNew: 0, FindingWorsened: 1, FindingImproved: 2, FindingResolved: 3, FindingUnchanged: 4}
+	sort.Slice(cmp.Findings, func(i, j int) bool {
+		a, b := cmp.Findings[i], cmp.Findings[j]
+		if order[a.Status] != order[b.Status] {
+			return order[a.Status] < order[b.Status]
+		}
+		return pvcKey(a.Namespace, a.Name) < pvcKey(b.Namespace, b.Name)
+	})
+	return cmp
+}
+
+func formatDeltaMB(deltaMB int64) string {
+	sign := "+"
+	if deltaMB < 0 {
+		sign = "-"
+		deltaMB = -deltaMB
+	}
+	if deltaMB >= 1024 {
+		return fmt.Sprintf("%s%.2f GB", sign, float64(deltaMB)/1024)
+	}
+	return fmt.Sprintf("%s%d MB", sign, deltaMB)
+}
+
+// BaselineSummary renders the baseline comparison section of the CLI report
+func BaselineSummary(cmp *BaselineComparison) string {
+	if cmp == nil {
+		return ""
+	}
+	report := strings.Builder{}
+
+	report.WriteString("\n🔁 Baseline Comparison\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	report.WriteString(fmt.Sprintf("Baseline Report          : %s (%s)\n", cmp.BaselineFile, cmp.BaselineGeneratedAt))
+	report.WriteString(fmt.Sprintf("New Findings             : %d\n", cmp.New))
+	report.WriteString(fmt.Sprintf("Worsened Findings        : %d\n", cmp.Worsened))
+	report.WriteString(fmt.Sprintf("Improved Findings        : %d\n", cmp.Improved))
+	report.WriteString(fmt.Sprintf("Resolved Findings        : %d\n", cmp.Resolved))
+	report.WriteString(fmt.Sprintf("Unchanged Findings       : %d\n", cmp.Unchanged))
+	report.WriteString(fmt.Sprintf("Net Waste Delta          : %s\n", formatDeltaMB(cmp.NetWasteDeltaMB)))
+	report.WriteString(fmt.Sprintf("Findings Waste Delta     : %s\n", formatDeltaMB(cmp.FindingWasteDeltaMB)))
+
+	for _, f := range cmp.Findings {
+		if f.Status == FindingUnchanged {
+			continue
+		}
+		switch f.Status {
+		case FindingNew:
+			report.WriteString(fmt.Sprintf("  🆕 %-9s %s/%s — %s, wasted %s\n", f.Status, f.Namespace, f.Name, f.Category, formatDeltaMB(f.WastedMB)))
+		case FindingWorsened:
+			report.WriteString(fmt.Sprintf("  📈 %-9s %s/%s — %s → %s, wasted %s\n", f.Status, f.Namespace, f.Name, f.BaselineCategory, f.Category, formatDeltaMB(f.WastedMB-f.BaselineWastedMB)))
+		case FindingImproved:
+			report.WriteString(fmt.Sprintf("  📉 %-9s %s/%s — %s → %s, wasted %s\n", f.Status, f.Namespace, f.Name, f.BaselineCategory, f.Category, formatDeltaMB(f.WastedMB-f.BaselineWastedMB)))
+		case FindingResolved:
+			report.WriteString(fmt.Sprintf("  ✅ %-9s %s/%s — was %s\n", f.Status, f.Namespace, f.Name, f.BaselineCategory))
+		}
+	}
+
+	return report.String()
+}
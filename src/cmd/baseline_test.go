@@ -0,0 +1,108 @@
+package cmd
+
+import "testing"
+
+func testReport(all bool, namespaces []string, pvcs ...PVCInfo) ClusterReport {
+	report := ClusterReport{AllNamespaces: all, Namespaces: namespaces}
+	byNamespace := map[string]int{}
+	for _, pvc := range pvcs {
+		i, ok := byNamespace[pvc.Namespace]
+		if !ok {
+			i = len(report.NamespaceReports)
+			byNamespace[pvc.Namespace] = i
+			report.NamespaceReports = append(report.NamespaceReports, NamespaceReport{Namespace: pvc.Namespace})
+		}
+		report.NamespaceReports[i].PVCs = append(report.NamespaceReports[i].PVCs, pvc)
+	}
+	return report
+}
+
+func TestCompareWithBaseline(t *testing.T) {
+	pvc := func(ns, name, category string, wastedMB int64) PVCInfo {
+		return PVCInfo{Namespace: ns, Name: name, Category: category, WastedMB: wastedMB}
+	}
+	tests := []struct {
+		name     string
+		current  ClusterReport
+		baseline ClusterReport
+		want     map[string]string // namespace/name → status
+	}{
+		{
+			name:     "new finding",
+			current:  testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 500)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryHealthy, 0)),
+			want:     map[string]string{"a/data": FindingNew},
+		},
+		{
+			name:     "resolved finding",
+			current:  testReport(true, nil, pvc("a", "data", CategoryHealthy, 0)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryIdle, 500)),
+			want:     map[string]string{"a/data": FindingResolved},
+		},
+		{
+			name:     "more waste is worse",
+			current:  testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 900)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 500)),
+			want:     map[string]string{"a/data": FindingWorsened},
+		},
+		{
+			name:     "less waste is improved",
+			current:  testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 100)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 500)),
+			want:     map[string]string{"a/data": FindingImproved},
+		},
+		{
+			name:     "more severe category is worse despite less waste",
+			current:  testReport(true, nil, pvc("a", "data", CategoryAbandoned, 100)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryIdle, 500)),
+			want:     map[string]string{"a/data": FindingWorsened},
+		},
+		{
+			name:     "less severe category is improved",
+			current:  testReport(true, nil, pvc("a", "data", CategoryOverProvisioned, 900)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryFillingFast, 0)),
+			want:     map[string]string{"a/data": FindingImproved},
+		},
+		{
+			name:     "unchanged",
+			current:  testReport(true, nil, pvc("a", "data", CategoryIdle, 500)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryIdle, 500)),
+			want:     map[string]string{"a/data": FindingUnchanged},
+		},
+		{
+			name:     "namespaced run against a cluster-wide baseline",
+			current:  testReport(false, []string{"a"}, pvc("a", "data", CategoryIdle, 500)),
+			baseline: testReport(true, nil, pvc("a", "data", CategoryIdle, 500), pvc("b", "logs", CategoryOrphaned, 100)),
+			want:     map[string]string{"a/data": FindingUnchanged},
+		},
+		{
+			name:     "cluster-wide run against a namespaced baseline",
+			current:  testReport(true, nil, pvc("a", "data", CategoryIdle, 500), pvc("b", "logs", CategoryOrphaned, 100)),
+			baseline: testReport(false, []string{"a"}, pvc("a", "data", CategoryIdle, 500)),
+			want:     map[string]string{"a/data": FindingUnchanged},
+		},
+		{
+			name:     "namespace emptied since the baseline",
+			current:  testReport(false, []string{"a", "b"}, pvc("a", "data", CategoryIdle, 500)),
+			baseline: testReport(false, []string{"a", "b"}, pvc("a", "data", CategoryIdle, 500), pvc("b", "logs", CategoryOrphaned, 100)),
+			want:     map[string]string{"a/data": FindingUnchanged, "b/logs": FindingResolved},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmp := CompareWithBaseline(tt.current, tt.baseline, "baseline.json")
+			got := map[string]string{}
+			for _, f := range cmp.Findings {
+				got[pvcKey(f.Namespace, f.Name)] = f.Status
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+			for key, status := range tt.want {
+				if got[key] != status {
+					t.Errorf("%s = %q, want %q", key, got[key], status)
+				}
+			}
+		})
+	}
+}
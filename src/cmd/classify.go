@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"pvc-audit/util"
+	"strings"
+	"time"
+)
+
+// PVC categories used across reports, CSV and metrics
+const (
+	CategoryHealthy          = "Healthy"
+	CategoryCritical         = "Critical"
+	CategoryOverProvisioned  = "Over-provisioned"
+	CategoryNewlyProvisioned = "Newly provisioned"
+	CategoryIdle             = "Idle"
+	CategoryAbandoned        = "Abandoned"
+	CategoryDormant          = "Dormant"
+	CategoryOrphaned         = "Orphaned"
+	CategoryScaleDownLeft    = "Scale-down leftover"
+	CategoryFillingFast      = "Filling fast"
+)
+
+var (
+	gracePeriod  time.Duration // new PVCs are not flagged before this age
+	abandonAfter time.Duration // unattached PVCs without pod activity for this long are abandoned
+)
+
+// ClassifyPVC assigns a category from usage, growth, attachment, workload and
+// lifecycle information. PVCs projected to be full soon are Filling fast.
+// StatefulSet scale-down leftovers are reported as such; otherwise PVCs
+// younger than the grace period are never flagged as wasteful.
+// Unused PVCs are Idle when mounted by a running pod, Dormant when a workload
+// still references them, and otherwise Orphaned or, past --abandon-after, Abandoned.
+func ClassifyPVC(pvc PVCInfo, now time.Time) string {
+	if IsFillingFast(pvc) {
+		return CategoryFillingFast
+	}
+
+	category := CategoryHealthy
+	unused := false
+
+	if !pvc.Attached && pvc.UsedPct <= 5 {
+		unused = true
+	} else if pvc.WastagePct == 100 {
+		unused = true
+	} else if pvc.WastagePct <= 10 {
+		category = CategoryCritical
+	} else if pvc.WastagePct >= 70 {
+		category = CategoryOverProvisioned
+	}
+
+	if category == CategoryCritical {
+		return category
+	}
+	if pvc.ScaleDownLeftover != "" && !pvc.Attached {
+		return CategoryScaleDownLeft
+	}
+	if (unused || category == CategoryOverProvisioned) && !pvc.CreatedAt.IsZero() && now.Sub(pvc.CreatedAt) < gracePeriod {
+		return CategoryNewlyProvisioned
+	}
+	if !unused {
+		return category
+	}
+
+	if pvc.Attached {
+		return CategoryIdle
+	}
+	if len(pvc.ReferencedBy) > 0 {
+		return CategoryDormant
+	}
+	if now.Sub(pvc.LastActivityAt()) >= abandonAfter {
+		return CategoryAbandoned
+	}
+	return CategoryOrphaned
+}
+
+// IsFlaggable reports whether a category may be listed as a wastage or cleanup finding
+func IsFlaggable(category string) bool {
+	return category != CategoryNewlyProvisioned && category != CategoryDormant && category != CategoryCritical &&
+		category != CategoryHealthy && category != CategoryFillingFast
+}
+
+// LastActivityAt is the latest of creation, PV bind and pod activity time
+func (p PVCInfo) LastActivityAt() time.Time {
+	last := p.CreatedAt
+	if p.BoundAt.After(last) {
+		last = p.BoundAt
+	}
+	if p.LastPodActivity.After(last) {
+		last = p.LastPodActivity
+	}
+	return last
+}
+
+// FormatAge renders a duration as a compact age like kubectl (5m, 3h, 12d)
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "-"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// ScaleDownLeftoverSummary renders the StatefulSet scale-down leftovers section of the CLI report
+func ScaleDownLeftoverSummary(clusterReport ClusterReport) string {
+	if len(clusterReport.ScaleDownLeftovers) == 0 {
+		return ""
+	}
+	report := strings.Builder{}
+
+	var reclaimableMB int64
+	report.WriteString("\n🪣 StatefulSet Scale-down Leftovers\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	for _, pvc := range clusterReport.ScaleDownLeftovers {
+		allocVal, allocUnit := util.FormatSizeMBorGB(pvc.AllocatedMB)
+		report.WriteString(fmt.Sprintf("  %s/%s — %s, reclaimable %.2f %s\n",
+			pvc.Namespace, pvc.Name, pvc.ScaleDownLeftover, allocVal, allocUnit))
+		reclaimableMB += pvc.AllocatedMB
+	}
+	totalVal, totalUnit := util.FormatSizeMBorGB(reclaimableMB)
+	report.WriteString(fmt.Sprintf("Total Reclaimable        : %.2f %s\n", totalVal, totalUnit))
+
+	return report.String()
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyPVC(t *testing.T) {
+	gracePeriod, abandonAfter, fillingFastAfter = 7*24*time.Hour, 30*24*time.Hour, 14
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-90 * 24 * time.Hour)
+
+	tests := []struct {
+		name string
+		pvc  PVCInfo
+		want string
+	}{
+		{"healthy", PVCInfo{Attached: true, UsedPct: 50, WastagePct: 50, CreatedAt: old}, CategoryHealthy},
+		{"critical", PVCInfo{Attached: true, UsedPct: 95, WastagePct: 5, CreatedAt: old}, CategoryCritical},
+		{"over-provisioned", PVCInfo{Attached: true, UsedPct: 20, WastagePct: 80, CreatedAt: old}, CategoryOverProvisioned},
+		{"newly provisioned", PVCInfo{Attached: true, UsedPct: 20, WastagePct: 80, CreatedAt: now.Add(-24 * time.Hour)}, CategoryNewlyProvisioned},
+		{"idle", PVCInfo{Attached: true, WastagePct: 100, CreatedAt: old}, CategoryIdle},
+		{"dormant", PVCInfo{ReferencedBy: []string{"Deployment/api (0 replicas)"}, CreatedAt: old}, CategoryDormant},
+		{"orphaned", PVCInfo{CreatedAt: old, LastPodActivity: now.Add(-10 * 24 * time.Hour)}, CategoryOrphaned},
+		{"abandoned", PVCInfo{CreatedAt: old, LastPodActivity: now.Add(-40 * 24 * time.Hour)}, CategoryAbandoned},
+		{"unattached but used is not idle", PVCInfo{UsedPct: 40, WastagePct: 60, CreatedAt: old}, CategoryHealthy},
+		{"scale-down leftover", PVCInfo{ScaleDownLeftover: "ordinal 3 ≥ 3 replicas", CreatedAt: old}, CategoryScaleDownLeft},
+		{"scale-down leftover still mounted", PVCInfo{Attached: true, WastagePct: 100, ScaleDownLeftover: "ordinal 3 ≥ 3 replicas", CreatedAt: old}, CategoryIdle},
+		{"filling fast", PVCInfo{Attached: true, UsedPct: 60, WastagePct: 40, Forecasted: true, GrowthMBPerDay: 100, DaysUntilFull: 5, CreatedAt: old}, CategoryFillingFast},
+		{"growing slowly", PVCInfo{Attached: true, UsedPct: 60, WastagePct: 40, Forecasted: true, GrowthMBPerDay: 1, DaysUntilFull: 200, CreatedAt: old}, CategoryHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPVC(tt.pvc, now); got != tt.want {
+				t.Errorf("ClassifyPVC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
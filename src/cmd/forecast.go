@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+)
+
+var (
+	historyDir       string        // saved audit reports used as usage history
+	prometheusURL    string        // Prometheus with kubelet volume stats, used as usage history
+	historyWindow    time.Duration // how far back history is considered
+	fillingFastAfter int           // PVCs projected full within this many days are Filling fast
+)
+
+// minForecastSpan is the shortest history a growth trend is fitted on
+const minForecastSpan = 24 * time.Hour
+
+// maxPrometheusPoints caps the number of samples per PVC requested from Prometheus
+const maxPrometheusPoints = 250
+
+// UsageSample is the used space of a PVC at a point in time
+type UsageSample struct {
+	At     time.Time
+	UsedMB int64
+}
+
+// UsageHistory holds usage samples per PVC, keyed by namespace/pvc
+type UsageHistory map[string][]UsageSample
+
+// Forecast is the growth trend of a PVC fitted on its usage history
+type Forecast struct {
+	GrowthMBPerDay float64   // Least-squares growth rate
+	DaysUntilFull  float64   // Days until the allocation is used up (-1 if not growing)
+	FullAt         time.Time // Projected full date (zero if not growing)
+	PeakUsedMB     int64     // Highest used space in the history
+	Samples        int
+}
+
+// LoadReportHistory reads the usage of attached PVCs from saved JSON audit
+// reports in dir generated after since
+func LoadReportHistory(dir string, since time.Time) (UsageHistory, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "pvc-wastage-report-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	history := UsageHistory{}
+	for _, file := range files {
+		report, err := LoadJSONReport(file)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping history report: %v\n", err)
+			continue
+		}
+		at, err := time.ParseInLocation("2006-01-02 15:04:05", report.GeneratedAt, time.Local)
+		if err != nil || at.Before(since) {
+			continue
+		}
+		for _, nsReport := range report.NamespaceReports {
+			for _, pvc := range nsReport.PVCs {
+				// usage is only measured while a pod mounts the PVC
+				if !pvc.Attached {
+					continue
+				}
+				key := pvcKey(nsReport.Namespace, pvc.Name)
+				history[key] = append(history[key], UsageSample{At: at, UsedMB: pvc.UsedMB})
+			}
+		}
+	}
+	return history, nil
+}
+
+// LoadPrometheusHistory reads the kubelet volume stats of every PVC from
+// Prometheus between since and now
+func LoadPrometheusHistory(url string, since, now time.Time) (UsageHistory, error) {
+	step := now.Sub(since) / maxPrometheusPoints
+	if step < 5*time.Minute {
+		step = 5 * time.Minute
+	}
+	series, err := internal.QueryVolumeUsageRange(nil, url, since, now, step)
+	if err != nil {
+		return nil, err
+	}
+
+	history := UsageHistory{}
+	for key, samples := range series {
+		for _, s := range samples {
+			history[key] = append(history[key], UsageSample{At: s.At, UsedMB: int64(s.UsedBytes / 1024 / 1024)})
+		}
+	}
+	return history, nil
+}
+
+// Merge adds the samples of other to the history
+func (h UsageHistory) Merge(other UsageHistory) {
+	for key, samples := range other {
+		h[key] = append(h[key], samples...)
+	}
+}
+
+// PeakUsage returns the highest used space of the samples
+func PeakUsage(samples []UsageSample) int64 {
+	var peak int64
+	for _, s := range samples {
+		if s.UsedMB > peak {
+			peak = s.UsedMB
+		}
+	}
+	return peak
+}
+
+// FitGrowth fits a least-squares growth trend on the history of a PVC plus
+// its current usage. ok is false when the history spans less than a day.
+func FitGrowth(samples []UsageSample, current UsageSample, allocatedMB int64) (Forecast, bool) {
+	all := append(append([]UsageSample{}, samples...), current)
+	sort.Slice(all, func(i, j int) bool { return all[i].At.Before(all[j].At) })
+
+	forecast := Forecast{DaysUntilFull: -1, Samples: len(all), PeakUsedMB: PeakUsage(all)}
+	if len(all) < 2 || all[len(all)-1].At.Sub(all[0].At) < minForecastSpan {
+		return forecast, false
+	}
+
+	// x in days since the first sample, y in MB
+	origin := all[0].At
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(all))
+	for _, s := range all {
+		x := s.At.Sub(origin).Hours() / 24
+		y := float64(s.UsedMB)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return forecast, false
+	}
+	forecast.GrowthMBPerDay = (n*sumXY - sumX*sumY) / denom
+
+	if forecast.GrowthMBPerDay > 0 {
+		free := float64(allocatedMB - current.UsedMB)
+		if free < 0 {
+			free = 0
+		}
+		forecast.DaysUntilFull = free / forecast.GrowthMBPerDay
+		forecast.FullAt = current.At.Add(time.Duration(forecast.DaysUntilFull * 24 * float64(time.Hour)))
+	}
+	return forecast, true
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// IsFillingFast reports whether a PVC is projected to be full within --filling-fast days
+func IsFillingFast(pvc PVCInfo) bool {
+	return pvc.Forecasted && pvc.GrowthMBPerDay > 0 && pvc.DaysUntilFull <= float64(fillingFastAfter)
+}
+
+// FormatDaysUntilFull renders days-until-full for reports, "-" when not growing
+func FormatDaysUntilFull(pvc PVCInfo) string {
+	if !pvc.Forecasted || pvc.DaysUntilFull < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", pvc.DaysUntilFull)
+}
+
+// ForecastSummary renders the growth forecast section of the CLI report
+func ForecastSummary(clusterReport ClusterReport) string {
+	if len(clusterReport.FillingFastPVCs) == 0 {
+		return ""
+	}
+	report := strings.Builder{}
+
+	report.WriteString("\n📈 Growth Forecast — Filling Fast\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	for _, pvc := range clusterReport.FillingFastPVCs {
+		usedVal, usedUnit := util.FormatSizeMBorGB(pvc.UsedMB)
+		allocVal, allocUnit := util.FormatSizeMBorGB(pvc.AllocatedMB)
+		report.WriteString(fmt.Sprintf("  %s/%s — %.2f %s of %.2f %s, +%.0f MB/day, full in %s days (%s)\n",
+			pvc.Namespace, pvc.Name, usedVal, usedUnit, allocVal, allocUnit,
+			pvc.GrowthMBPerDay, FormatDaysUntilFull(pvc), pvc.FullAt.Format("2006-01-02")))
+	}
+
+	return report.String()
+}
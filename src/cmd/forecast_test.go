@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFitGrowth(t *testing.T) {
+	now := time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int, usedMB int64) UsageSample {
+		return UsageSample{At: now.Add(-time.Duration(days) * 24 * time.Hour), UsedMB: usedMB}
+	}
+
+	tests := []struct {
+		name          string
+		samples       []UsageSample
+		current       UsageSample
+		allocatedMB   int64
+		ok            bool
+		growth        float64
+		daysUntilFull float64
+		peak          int64
+	}{
+		{"no history", nil, daysAgo(0, 500), 1000, false, 0, -1, 500},
+		{"history under a day", []UsageSample{{At: now.Add(-time.Hour), UsedMB: 400}}, daysAgo(0, 500), 1000, false, 0, -1, 500},
+		{"linear growth", []UsageSample{daysAgo(2, 300), daysAgo(1, 400)}, daysAgo(0, 500), 1000, true, 100, 5, 500},
+		{"unsorted history", []UsageSample{daysAgo(1, 400), daysAgo(2, 300)}, daysAgo(0, 500), 1000, true, 100, 5, 500},
+		{"shrinking", []UsageSample{daysAgo(2, 900), daysAgo(1, 700)}, daysAgo(0, 500), 1000, true, -200, -1, 900},
+		{"flat", []UsageSample{daysAgo(2, 500), daysAgo(1, 500)}, daysAgo(0, 500), 1000, true, 0, -1, 500},
+		{"already full", []UsageSample{daysAgo(1, 1000)}, daysAgo(0, 1100), 1000, true, 100, 0, 1100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FitGrowth(tt.samples, tt.current, tt.allocatedMB)
+			if ok != tt.ok {
+				t.Fatalf("FitGrowth() ok = %v, want %v", ok, tt.ok)
+			}
+			if math.Abs(got.GrowthMBPerDay-tt.growth) > 1e-9 || math.Abs(got.DaysUntilFull-tt.daysUntilFull) > 1e-9 || got.PeakUsedMB != tt.peak {
+				t.Errorf("FitGrowth() = %+v, want growth %v, days until full %v, peak %d", got, tt.growth, tt.daysUntilFull, tt.peak)
+			}
+			if tt.daysUntilFull >= 0 {
+				if want := now.Add(time.Duration(tt.daysUntilFull * 24 * float64(time.Hour))); !got.FullAt.Equal(want) {
+					t.Errorf("FitGrowth() FullAt = %v, want %v", got.FullAt, want)
+				}
+			} else if !got.FullAt.IsZero() {
+				t.Errorf("FitGrowth() FullAt = %v, want zero", got.FullAt)
+			}
+		})
+	}
+}
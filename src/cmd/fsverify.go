@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Outcomes of the filesystem check after an expansion
+const (
+	FilesystemVerified  = "Verified"
+	FilesystemPending   = "ResizePending" // the volume grew but the mounted filesystem did not; a pod restart is needed
+	FilesystemFailed    = "Failed"
+	FilesystemUnchecked = "Unchecked" // no running pod mounts the PVC, or df could not run
+)
+
+const (
+	// fsVerifyTimeout is how long the kubelet gets to grow a mounted filesystem
+	// once the PVC reports the new capacity
+	fsVerifyTimeout = 2 * time.Minute
+	// fsVerifyPollInterval is how often df is rerun while waiting
+	fsVerifyPollInterval = 10 * time.Second
+	// fsExecTimeout bounds a single df in a pod
+	fsExecTimeout = 30 * time.Second
+)
+
+var errNoRunningPod = errors.New("no running pod mounts the PVC")
+
+// FilesystemCheck is the filesystem size seen from inside a pod mounting an expanded PVC
+type FilesystemCheck struct {
+	Status    string
+	Pod       string
+	PodUID    types.UID
+	MountPath string
+	Before    resource.Quantity // filesystem size before the expansion (zero when it was not measured)
+	Size      resource.Quantity // filesystem size after the expansion
+	Message   string
+}
+
+// measureFilesystem runs df in the first running pod mounting the PVC
+func measureFilesystem(ns, pvcName string) (FilesystemCheck, error) {
+	attachments, err := internal.FindPodAttachmentsForPVC(ns, pvcName)
+	if err != nil {
+		return FilesystemCheck{}, err
+	}
+	for _, a := range attachments {
+		if !a.Active() || a.MountPath == "" {
+			continue
+		}
+		check := FilesystemCheck{Pod: a.PodName, PodUID: a.UID, MountPath: a.MountPath}
+		ctx, cancel := context.WithTimeout(context.Background(), fsExecTimeout)
+		bytes, err := internal.FilesystemSizeInPod(ctx, ns, a.PodName, a.Container, a.MountPath)
+		cancel()
+		if err != nil {
+			return check, fmt.Errorf("df in pod %s: %v", a.PodName, err)
+		}
+		check.Size = *resource.NewQuantity(bytes, resource.BinarySI)
+		return check, nil
+	}
+	return FilesystemCheck{}, errNoRunningPod
+}
+
+// measureUsedBytes returns the bytes used on a PVC, measured with df in the
+// first running pod mounting it; a PVC mounted by several pods is measured
+// once. It fails when no pod mounts the PVC or df cannot run, so that callers
+// never mistake a missing measurement for an empty volume.
+func measureUsedBytes(ns, pvcName string) (int64, error) {
+	attachments, err := internal.FindPodAttachmentsForPVC(ns, pvcName)
+	if err != nil {
+		return 0, err
+	}
+	for _, a := range attachments {
+		if !a.Active() || a.MountPath == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), fsExecTimeout)
+		_, used, err := internal.FilesystemUsageInPod(ctx, ns, a.PodName, a.Container, a.MountPath)
+		cancel()
+		if err != nil {
+			return 0, fmt.Errorf("df in pod %s: %v", a.PodName, err)
+		}
+		return used, nil
+	}
+	return 0, errNoRunningPod
+}
+
+// filesystemExpanded reports whether the filesystem grew with the volume:
+// by 90% of the expansion when its size before is known (filesystems keep
+// part of the volume for metadata), otherwise to 90% of the new size
+func filesystemExpanded(before, after, from, to resource.Quantity) bool {
+	grow := to.Value() - from.Value()
+	if !before.IsZero() {
+		return after.Value()-before.Value() >= grow/10*9
+	}
+	return after.Value() >= to.Value()/10*9
+}
+
+// VerifyFilesystem re-measures the filesystem of an expanded PVC from inside
+// the pod mounting it, waiting up to wait for the kubelet to grow it, and
+// compares it against the new capacity
+func VerifyFilesystem(result ResizeResult, before resource.Quantity, wait time.Duration, logf Logf) FilesystemCheck {
+	deadline := time.Now().Add(wait)
+	for {
+		check, err := measureFilesystem(result.Namespace, result.PVC)
+		check.Before = before
+		if err == errNoRunningPod {
+			check.Status = FilesystemUnchecked
+			check.Message = "no running pod mounts the PVC, the filesystem grows when one mounts it"
+			return check
+		}
+		if err != nil {
+			check.Status = FilesystemUnchecked
+			check.Message = err.Error()
+			return check
+		}
+		if filesystemExpanded(before, check.Size, result.From, result.To) {
+			check.Status = FilesystemVerified
+			check.Message = fmt.Sprintf("filesystem at %s in pod %s is %s", check.MountPath, check.Pod, check.Size.String())
+			return check
+		}
+
+		if pvc, err := internal.GetPVC(result.Namespace, result.PVC); err == nil {
+			if c := internal.PVCCondition(*pvc, corev1.PersistentVolumeClaimNodeResizeError); c != nil {
+				check.Status = FilesystemFailed
+				check.Message = fmt.Sprintf("%s: %s", c.Type, c.Message)
+				return check
+			}
+		}
+		if time.Now().After(deadline) {
+			check.Status = FilesystemPending
+			check.Message = fmt.Sprintf("filesystem at %s in pod %s is still %s for a %s volume; restart the pod to grow it (the CSI driver may only expand offline)",
+				check.MountPath, check.Pod, check.Size.String(), result.To.String())
+			return check
+		}
+		logf("filesystem at %s in pod %s is still %s, waiting for the kubelet to grow it", check.MountPath, check.Pod, check.Size.String())
+		time.Sleep(fsVerifyPollInterval)
+	}
+}
+
+// RestartForFilesystemResize deletes the pod of a pending filesystem resize,
+// so that the kubelet grows the filesystem when its controller recreates it,
+// then verifies the filesystem in the new pod. Bare pods are not deleted.
+func RestartForFilesystemResize(result *ResizeResult, timeout time.Duration, logf Logf) error {
+	check := result.Filesystem
+	pod, err := internal.GetPod(result.Namespace, check.Pod)
+	if err != nil {
+		return fmt.Errorf("getting pod %s/%s: %v", result.Namespace, check.Pod, err)
+	}
+	if pod.UID != check.PodUID {
+		return fmt.Errorf("pod %s/%s was already replaced", result.Namespace, check.Pod)
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return fmt.Errorf("pod %s/%s has no controller to recreate it, restart it manually", result.Namespace, check.Pod)
+	}
+	if err := internal.DeletePod(result.Namespace, pod.Name, pod.UID); err != nil {
+		return fmt.Errorf("deleting pod %s/%s: %v", result.Namespace, pod.Name, err)
+	}
+	logf("pod %s deleted, waiting for %s %s to recreate it", pod.Name, owner.Kind, owner.Name)
+
+	err = waitFor(fmt.Sprintf("a new pod mounting PVC %s", result.PVC), timeout, func() (bool, error) {
+		attachments, err := internal.FindPodAttachmentsForPVC(result.Namespace, result.PVC)
+		if err != nil {
+			return false, err
+		}
+		for _, a := range attachments {
+			if a.Active() && a.UID != pod.UID {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Filesystem = VerifyFilesystem(*result, check.Before, fsVerifyTimeout, logf)
+	if result.Filesystem.Status == FilesystemPending {
+		result.Filesystem.Status = FilesystemFailed
+		result.Filesystem.Message = fmt.Sprintf("filesystem at %s in pod %s is still %s after the restart",
+			result.Filesystem.MountPath, result.Filesystem.Pod, result.Filesystem.Size.String())
+	}
+	return nil
+}
+
+// settleFilesystem restarts the pod of a pending filesystem resize when
+// restart is set, and reports a failed filesystem check as an error
+func settleFilesystem(result *ResizeResult, restart bool, timeout time.Duration, logf Logf) error {
+	if result.Filesystem.Status == FilesystemPending && restart {
+		if err := RestartForFilesystemResize(result, timeout, logf); err != nil {
+			return err
+		}
+		logf("filesystem %s: %s", result.Filesystem.Status, result.Filesystem.Message)
+	}
+	if result.Filesystem.Status == FilesystemFailed {
+		return fmt.Errorf("volume expanded but the filesystem did not grow: %s", result.Filesystem.Message)
+	}
+	return nil
+}
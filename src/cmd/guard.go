@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+var (
+	guardForce  bool
+	guardReason string
+)
+
+const (
+	// ledgerConfigMap records the changes made in governed namespaces
+	ledgerConfigMap = "spacio-change-ledger"
+	ledgerKey       = "changes.json"
+	// ledgerRetention is how long ledger entries are kept
+	ledgerRetention = 30 * 24 * time.Hour
+	// maxWindowDuration bounds how far back a window start is searched
+	maxWindowDuration = 7 * 24 * time.Hour
+)
+
+// Change is a storage change submitted to the guard
+type Change struct {
+	Namespace string
+	Name      string // PVC, PV or StatefulSet
+	Action    string // expand, migrate, delete, release, ...
+	Bytes     int64  // storage added, removed or moved
+	Count     int    // volumes changed (0 counts as 1)
+}
+
+func (c Change) count() int {
+	if c.Count <= 0 {
+		return 1
+	}
+	return c.Count
+}
+
+// LedgerEntry is a change recorded in the change ledger
+type LedgerEntry struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Action    string    `json:"action"`
+	Bytes     int64     `json:"bytes"`
+	Count     int       `json:"count"`
+	Window    string    `json:"window,omitempty"` // window the change counted against
+	User      string    `json:"user,omitempty"`
+	Forced    bool      `json:"forced,omitempty"`
+	Reason    string    `json:"reason,omitempty"`  // why the guard was overridden
+	ID        string    `json:"id,omitempty"`      // reservation made at admission
+	Pending   bool      `json:"pending,omitempty"` // admitted, not yet confirmed by Record
+}
+
+// Admission is the guard's decision to let a change through
+type Admission struct {
+	Change   Change
+	Governed bool   // the namespace is covered by a maintenance window
+	Window   string // window the change counts against
+	Forced   bool   // outside every window or budget, let through by --force
+	Reason   string
+	ID       string // ledger reservation to confirm with Record or drop with Release
+}
+
+// DisplayName identifies a window in messages
+func (w MaintenanceWindow) DisplayName() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return fmt.Sprintf("%q for %s", w.Schedule, w.Duration)
+}
+
+func (w MaintenanceWindow) validate() error {
+	if _, err := util.ParseCron(w.Schedule); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(w.Duration)
+	if err != nil || d <= 0 || d > maxWindowDuration {
+		return fmt.Errorf("duration %q must be positive and at most %s", w.Duration, maxWindowDuration)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", w.Timezone)
+	}
+	if w.MaxChanges < 0 {
+		return fmt.Errorf("maxChanges must not be negative")
+	}
+	if w.MaxBytes != "" {
+		if _, err := resource.ParseQuantity(w.MaxBytes); err != nil {
+			return fmt.Errorf("invalid maxBytes %q", w.MaxBytes)
+		}
+	}
+	return nil
+}
+
+// OpenSince returns the start of the window occurrence that contains now;
+// the window was validated on load
+func (w MaintenanceWindow) OpenSince(now time.Time) (time.Time, bool) {
+	schedule, _ := util.ParseCron(w.Schedule)
+	duration, _ := time.ParseDuration(w.Duration)
+	loc, _ := time.LoadLocation(w.Timezone)
+	start, ok := schedule.LastStart(now.In(loc), duration)
+	if !ok || !now.Before(start.Add(duration)) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// Guard admits mutating actions inside maintenance windows and change budgets
+type Guard struct {
+	policy GovernancePolicy
+	force  bool
+	reason string
+	// update rewrites the change ledger; it retries when a concurrent writer
+	// got there first, which serializes admissions across processes
+	update func(mutate func(old string) (string, error)) error
+}
+
+// NewGuard builds the guard; --force requires a reason, which is recorded
+func NewGuard(policy GovernancePolicy, force bool, reason string) (*Guard, error) {
+	if force && strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("--force requires --force-reason explaining why the maintenance window or budget is overridden")
+	}
+	g := &Guard{policy: policy, force: force, reason: reason}
+	g.update = func(mutate func(old string) (string, error)) error {
+		return internal.UpdateConfigMapData(g.ledgerNamespace(), ledgerConfigMap, ledgerKey, mutate)
+	}
+	return g, nil
+}
+
+// newCommandGuard builds the guard and the hooks of a mutating command from
+// --policy, --force and --force-reason
+func newCommandGuard() (*Guard, *Hooks, error) {
+	policy, err := LoadPolicy(policyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	guard, err := NewGuard(policy.Governance, guardForce, guardReason)
+	return guard, NewHooks(policy.Hooks), err
+}
+
+func (g *Guard) ledgerNamespace() string {
+	if g.policy.LedgerNamespace != "" {
+		return g.policy.LedgerNamespace
+	}
+	return "default"
+}
+
+// updateLedger rewrites the ledger entries with mutate, pruning entries past the retention
+func (g *Guard) updateLedger(now time.Time, mutate func(entries []LedgerEntry) ([]LedgerEntry, error)) error {
+	return g.update(func(old string) (string, error) {
+		var entries []LedgerEntry
+		if old != "" {
+			if err := json.Unmarshal([]byte(old), &entries); err != nil {
+				return "", fmt.Errorf("reading change ledger %s/%s: %v", g.ledgerNamespace(), ledgerConfigMap, err)
+			}
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if now.Sub(e.Time) < ledgerRetention {
+				kept = append(kept, e)
+			}
+		}
+		entries, err := mutate(kept)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		return string(data), err
+	})
+}
+
+// Admit checks a change against the maintenance windows of its namespace: it
+// is admitted when one window is open and its budget leaves room for the
+// change. Namespaces without windows are not restricted. Refused changes are
+// admitted anyway with --force, flagged so that the reason is recorded.
+//
+// A governed change is reserved in the ledger by the same write that checks
+// the budget, so concurrent admissions — in flight or in other processes —
+// count against each other. The reservation must be confirmed with Record
+// once the change is made, or dropped with Release when it is not.
+func (g *Guard) Admit(c Change, now time.Time) (Admission, error) {
+	var windows []MaintenanceWindow
+	for _, w := range g.policy.Windows {
+		if globMatch(w.Namespace, c.Namespace) {
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == 0 {
+		return Admission{Change: c}, nil
+	}
+
+	var admission Admission
+	err := g.updateLedger(now, func(entries []LedgerEntry) ([]LedgerEntry, error) {
+		var err error
+		admission, err = g.admit(c, windows, entries, now)
+		if err != nil {
+			return nil, err
+		}
+		return append(entries, g.entry(admission, now, true)), nil
+	})
+	if err != nil {
+		return Admission{}, err
+	}
+	return admission, nil
+}
+
+// admit picks the first open window whose budget, counting the changes
+// already recorded or reserved since it opened, leaves room for the change
+func (g *Guard) admit(c Change, windows []MaintenanceWindow, entries []LedgerEntry, now time.Time) (Admission, error) {
+	var refusals []string
+	for _, w := range windows {
+		start, open := w.OpenSince(now)
+		if !open {
+			refusals = append(refusals, "outside window "+w.DisplayName())
+			continue
+		}
+		count, bytes := 0, int64(0)
+		for _, e := range entries {
+			if e.Window == w.DisplayName() && !e.Time.Before(start) {
+				count += e.Count
+				bytes += e.Bytes
+			}
+		}
+		if w.MaxChanges > 0 && count+c.count() > w.MaxChanges {
+			refusals = append(refusals, fmt.Sprintf("window %s already changed %d of %d volumes", w.DisplayName(), count, w.MaxChanges))
+			continue
+		}
+		if w.MaxBytes != "" {
+			max := resource.MustParse(w.MaxBytes)
+			if bytes+c.Bytes > max.Value() {
+				used := resource.NewQuantity(bytes, resource.BinarySI)
+				refusals = append(refusals, fmt.Sprintf("window %s already changed %s of %s", w.DisplayName(), used.String(), w.MaxBytes))
+				continue
+			}
+		}
+		return Admission{Change: c, Governed: true, Window: w.DisplayName(), ID: rand.String(12)}, nil
+	}
+
+	if g.force {
+		return Admission{Change: c, Governed: true, Forced: true, Reason: g.reason, ID: rand.String(12)}, nil
+	}
+	return Admission{}, fmt.Errorf("%s of %s/%s refused: %s (override with --force --force-reason)",
+		c.Action, c.Namespace, c.Name, strings.Join(refusals, "; "))
+}
+
+func (g *Guard) entry(a Admission, now time.Time, pending bool) LedgerEntry {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "spacio"
+	}
+	return LedgerEntry{
+		Time:      now.UTC(),
+		Namespace: a.Change.Namespace,
+		Name:      a.Change.Name,
+		Action:    a.Change.Action,
+		Bytes:     a.Change.Bytes,
+		Count:     a.Change.count(),
+		Window:    a.Window,
+		User:      user,
+		Forced:    a.Forced,
+		Reason:    a.Reason,
+		ID:        a.ID,
+		Pending:   pending,
+	}
+}
+
+// Record confirms the reservation of an admitted change once it was made.
+// The entry keeps the admission time, so the change counts against the
+// window that admitted it; a lost reservation is recorded anew.
+func (g *Guard) Record(a Admission, now time.Time) error {
+	if !a.Governed {
+		return nil
+	}
+	return g.updateLedger(now, func(entries []LedgerEntry) ([]LedgerEntry, error) {
+		for i := range entries {
+			if entries[i].ID == a.ID {
+				entries[i].Pending = false
+				return entries, nil
+			}
+		}
+		return append(entries, g.entry(a, now, false)), nil
+	})
+}
+
+// Release drops the reservation of an admitted change that was not made,
+// giving its share of the budget back
+func (g *Guard) Release(a Admission) error {
+	if !a.Governed {
+		return nil
+	}
+	return g.updateLedger(time.Now(), func(entries []LedgerEntry) ([]LedgerEntry, error) {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.ID != a.ID {
+				kept = append(kept, e)
+			}
+		}
+		return kept, nil
+	})
+}
+
+// releaseAdmission gives the budget reserved for a change that was not made back
+func releaseAdmission(guard *Guard, admission Admission, logf Logf) {
+	if err := guard.Release(admission); err != nil {
+		logf("⚠️  releasing the change budget reserved in the ledger: %v", err)
+	}
+}
+
+// madeError is the error of a change that failed after it was (at least
+// partly) made, e.g. an expansion whose storage request was already raised
+type madeError struct {
+	err error
+}
+
+func (e madeError) Error() string { return e.err.Error() }
+func (e madeError) Unwrap() error { return e.err }
+
+// changeMade marks err as raised after the change was made, so that the
+// guard still counts the change against the budget
+func changeMade(err error) error {
+	if err == nil {
+		return nil
+	}
+	return madeError{err: err}
+}
+
+// changeWasMade reports whether err was marked by changeMade
+func changeWasMade(err error) bool {
+	var made madeError
+	return errors.As(err, &made)
+}
+
+// Run admits a change and runs it, then confirms its reservation in the
+// ledger when it was made, even if it failed afterwards, or releases it when
+// it failed before anything was changed
+func (g *Guard) Run(c Change, logf Logf, run func() error) error {
+	admission, err := g.Admit(c, time.Now())
+	if err != nil {
+		return err
+	}
+	if admission.Forced {
+		logf("⚠️  %s of %s/%s forced outside the maintenance window or budget: %s", c.Action, c.Namespace, c.Name, admission.Reason)
+	}
+	err = run()
+	if err != nil && !changeWasMade(err) {
+		releaseAdmission(g, admission, logf)
+		return err
+	}
+	if err := g.Record(admission, time.Now()); err != nil {
+		logf("⚠️  recording the change in the ledger: %v", err)
+	}
+	return err
+}
+
+// addGuardFlags registers the flags of the shared guard on a mutating command
+func addGuardFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&policyFile, "policy", "", "Policy file (YAML or JSON) with maintenance windows, change budgets and hooks")
+	cmd.Flags().BoolVar(&guardForce, "force", false, "Run outside the maintenance window or change budget (requires --force-reason)")
+	cmd.Flags().StringVar(&guardReason, "force-reason", "", "Why the guard is overridden; recorded in the change ledger")
+}
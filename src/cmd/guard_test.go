@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryLedger backs a guard's change ledger with a string
+func memoryLedger(g *Guard) *string {
+	var ledger string
+	g.update = func(mutate func(old string) (string, error)) error {
+		value, err := mutate(ledger)
+		if err == nil {
+			ledger = value
+		}
+		return err
+	}
+	return &ledger
+}
+
+func TestGuardAdmit(t *testing.T) {
+	// Wednesday 2026-10-14 23:00 UTC, inside the nightly window opened at 22:00
+	now := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+	nightly := MaintenanceWindow{Name: "nightly", Namespace: "prod-*", Schedule: "0 22 * * *", Duration: "4h", MaxChanges: 2, MaxBytes: "10Gi"}
+	morning := MaintenanceWindow{Name: "morning", Namespace: "prod-*", Schedule: "0 6 * * *", Duration: "2h"}
+	const gi = int64(1) << 30
+
+	tests := []struct {
+		name     string
+		windows  []MaintenanceWindow
+		force    bool
+		changes  []Change // admitted in order; the last one is checked
+		admitted bool
+		governed bool
+		forced   bool
+		refusal  string
+	}{
+		{
+			name:     "namespace without window",
+			windows:  []MaintenanceWindow{nightly},
+			changes:  []Change{{Namespace: "dev", Name: "a", Bytes: 100 * gi}},
+			admitted: true,
+		},
+		{
+			name:     "inside the window and budget",
+			windows:  []MaintenanceWindow{nightly},
+			changes:  []Change{{Namespace: "prod-db", Name: "a", Bytes: gi}},
+			admitted: true,
+			governed: true,
+		},
+		{
+			name:    "outside every window",
+			windows: []MaintenanceWindow{morning},
+			changes: []Change{{Namespace: "prod-db", Name: "a", Bytes: gi}},
+			refusal: "outside window morning",
+		},
+		{
+			name:    "reserved changes count against the volume budget",
+			windows: []MaintenanceWindow{nightly},
+			changes: []Change{{Namespace: "prod-db", Name: "a"}, {Namespace: "prod-db", Name: "b"}, {Namespace: "prod-db", Name: "c"}},
+			refusal: "already changed 2 of 2 volumes",
+		},
+		{
+			name:    "reserved changes count against the byte budget",
+			windows: []MaintenanceWindow{nightly},
+			changes: []Change{{Namespace: "prod-db", Name: "a", Bytes: 6 * gi}, {Namespace: "prod-db", Name: "b", Bytes: 6 * gi}},
+			refusal: "already changed 6Gi of 10Gi",
+		},
+		{
+			name:     "forced outside the window",
+			windows:  []MaintenanceWindow{morning},
+			force:    true,
+			changes:  []Change{{Namespace: "prod-db", Name: "a", Bytes: gi}},
+			admitted: true,
+			governed: true,
+			forced:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGuard(GovernancePolicy{Windows: tt.windows}, tt.force, "incident 42")
+			if err != nil {
+				t.Fatal(err)
+			}
+			memoryLedger(g)
+			var a Admission
+			for _, c := range tt.changes {
+				c.Action = ActionExpand
+				a, err = g.Admit(c, now)
+			}
+			if !tt.admitted {
+				if err == nil || !strings.Contains(err.Error(), tt.refusal) {
+					t.Fatalf("Admit() error = %v, want refusal containing %q", err, tt.refusal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Admit() error = %v", err)
+			}
+			if a.Governed != tt.governed || a.Forced != tt.forced {
+				t.Errorf("Admit() = governed %v forced %v, want %v %v", a.Governed, a.Forced, tt.governed, tt.forced)
+			}
+		})
+	}
+}
+
+func TestGuardReleaseAndRecord(t *testing.T) {
+	now := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+	window := MaintenanceWindow{Name: "nightly", Schedule: "0 22 * * *", Duration: "4h", MaxChanges: 1}
+	g, err := NewGuard(GovernancePolicy{Windows: []MaintenanceWindow{window}}, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger := memoryLedger(g)
+	change := Change{Namespace: "db", Name: "data", Action: ActionExpand}
+
+	a, err := g.Admit(change, now)
+	if err != nil {
+		t.Fatalf("first Admit() error = %v", err)
+	}
+	if !strings.Contains(*ledger, `"pending": true`) {
+		t.Fatalf("admission not reserved in the ledger: %s", *ledger)
+	}
+	if _, err := g.Admit(change, now); err == nil {
+		t.Fatal("second Admit() admitted while the first change is in flight")
+	}
+	if err := g.Release(a); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = g.Admit(change, now); err != nil {
+		t.Fatalf("Admit() after Release error = %v", err)
+	}
+	if err := g.Record(a, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(*ledger, `"pending"`) || strings.Count(*ledger, `"id"`) != 1 {
+		t.Errorf("Record() did not confirm the reservation: %s", *ledger)
+	}
+	if _, err := g.Admit(change, now); err == nil {
+		t.Error("Admit() admitted past the budget after Record")
+	}
+}
+
+func TestGuardRunCountsChangesMadeBeforeFailing(t *testing.T) {
+	// a window open around the clock, as Run admits at the current time
+	window := MaintenanceWindow{Name: "always", Schedule: "* * * * *", Duration: "1h", MaxChanges: 1}
+	g, err := NewGuard(GovernancePolicy{Windows: []MaintenanceWindow{window}}, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger := memoryLedger(g)
+	change := Change{Namespace: "db", Name: "data", Action: ActionExpand}
+	logf := func(string, ...interface{}) {}
+
+	failed := errors.New("quota exceeded")
+	if err := g.Run(change, logf, func() error { return failed }); err != failed {
+		t.Fatalf("Run() error = %v, want %v", err, failed)
+	}
+	if strings.Contains(*ledger, `"id"`) {
+		t.Fatalf("a change failing before it was made was kept in the ledger: %s", *ledger)
+	}
+
+	timedOut := changeMade(errors.New("timed out"))
+	if err := g.Run(change, logf, func() error { return timedOut }); err != timedOut {
+		t.Fatalf("Run() error = %v, want %v", err, timedOut)
+	}
+	if strings.Contains(*ledger, `"pending"`) || strings.Count(*ledger, `"id"`) != 1 {
+		t.Fatalf("a change failing after it was made was not recorded: %s", *ledger)
+	}
+	if err := g.Run(change, logf, func() error { return nil }); err == nil {
+		t.Error("Run() ran past the budget after a change that failed once made")
+	}
+}
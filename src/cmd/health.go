@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxHealthEvents is how many of the latest events are shown per unhealthy PVC
+const maxHealthEvents = 3
+
+// CheckPVCHealth returns health information for a PVC that is not Bound
+// (Pending or Lost), with the latest events explaining why
+func CheckPVCHealth(pvc corev1.PersistentVolumeClaim, now time.Time) PVCHealth {
+	health := PVCHealth{
+		Namespace:   pvc.Namespace,
+		Name:        pvc.Name,
+		Phase:       string(pvc.Status.Phase),
+		AgeDays:     int(now.Sub(pvc.CreationTimestamp.Time).Hours() / 24),
+		Age:         FormatAge(now.Sub(pvc.CreationTimestamp.Time)),
+		RequestedMB: pvc.Spec.Resources.Requests.Storage().Value() / 1024 / 1024,
+	}
+	if pvc.Spec.StorageClassName != nil {
+		health.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if health.Phase == "" {
+		health.Phase = string(corev1.ClaimPending)
+	}
+
+	events, err := internal.ListEventsForObject(pvc.Namespace, "PersistentVolumeClaim", pvc.Name)
+	if err != nil {
+		health.Events = []string{err.Error()}
+		return health
+	}
+	for i, e := range events {
+		if i >= maxHealthEvents {
+			break
+		}
+		health.Events = append(health.Events, fmt.Sprintf("%s %s: %s (%s ago)",
+			e.Type, e.Reason, strings.TrimSpace(e.Message), FormatAge(now.Sub(internal.EventTime(e)))))
+	}
+	return health
+}
+
+// HealthSummary renders the PVC health section of the CLI report
+func HealthSummary(clusterReport ClusterReport) string {
+	if len(clusterReport.UnhealthyPVCs) == 0 {
+		return ""
+	}
+	report := strings.Builder{}
+
+	report.WriteString("\n🩺 PVC Health (not Bound)\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	report.WriteString(fmt.Sprintf("Pending PVCs             : %d\n", clusterReport.PendingPVCs))
+	report.WriteString(fmt.Sprintf("Lost PVCs                : %d\n", clusterReport.LostPVCs))
+	for _, h := range clusterReport.UnhealthyPVCs {
+		report.WriteString(fmt.Sprintf("  %s/%s — %s for %s (class %s)\n", h.Namespace, h.Name, h.Phase, h.Age, displayOrDash(h.StorageClass)))
+		for _, e := range h.Events {
+			report.WriteString(fmt.Sprintf("      ↳ %s\n", e))
+		}
+	}
+
+	return report.String()
+}
+
+func displayOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "List PVCs that are not Bound (Pending or Lost) with the events explaining why",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespaces := []string{}
+		if allNamespaces {
+			nsList, err := internal.ListNamespaces()
+			if err != nil {
+				return err
+			}
+			namespaces = nsList
+		} else {
+			namespaces = []string{namespace}
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Namespace", "PVC", "Phase", "Age", "Storage Class", "Requested", "Latest Events"})
+
+		now := time.Now()
+		for _, ns := range namespaces {
+			pvcs, err := internal.ListPVCs(ns)
+			if err != nil {
+				fmt.Printf("Error listing PVCs in namespace %s: %v\n", ns, err)
+				continue
+			}
+
+			for _, pvc := range pvcs {
+				if pvc.Status.Phase == corev1.ClaimBound {
+					continue
+				}
+				h := CheckPVCHealth(pvc, now)
+				events := "-"
+				if len(h.Events) > 0 {
+					events = strings.Join(h.Events, "\n")
+				}
+				t.AppendRow(table.Row{h.Namespace, h.Name, h.Phase, h.Age, displayOrDash(h.StorageClass), fmt.Sprintf("%d MB", h.RequestedMB), events})
+			}
+		}
+
+		if t.Length() == 0 {
+			fmt.Println("✅ All PVCs are Bound")
+			return nil
+		}
+
+		t.Render()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(healthCmd)
+	healthCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	healthCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Check PVCs in all namespaces")
+}
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations declaring the hooks of a PVC as a JSON or YAML list; they
+// replace the hooks of the same stage from the policy file, when the policy
+// accepts hook annotations (HookPolicy.Annotations)
+const (
+	AnnotationPreHooks  = "spacio.io/pre-hooks"
+	AnnotationPostHooks = "spacio.io/post-hooks"
+)
+
+// Hook stages
+const (
+	HookStagePre  = "pre"
+	HookStagePost = "post"
+)
+
+// What a failing pre hook does to the operation
+const (
+	HookAbort    = "abort"
+	HookContinue = "continue"
+)
+
+// defaultHookTimeout bounds a hook that sets no timeout
+const defaultHookTimeout = 30 * time.Second
+
+// ExecHook runs a command in a container of the pod consuming the PVC
+type ExecHook struct {
+	Container string   `json:"container,omitempty"` // default: the first container mounting the PVC
+	Command   []string `json:"command"`
+}
+
+// HTTPHook calls an endpoint with the HookPayload as JSON body; any status
+// other than 2xx is a failure
+type HTTPHook struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // default POST
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Hook is run before or after a volume operation; exactly one of Exec and HTTP is set
+type Hook struct {
+	Name      string    `json:"name,omitempty"`
+	Exec      *ExecHook `json:"exec,omitempty"`
+	HTTP      *HTTPHook `json:"http,omitempty"`
+	Timeout   string    `json:"timeout,omitempty"`   // default 30s
+	OnFailure string    `json:"onFailure,omitempty"` // pre hooks: abort (default) or continue
+}
+
+// HookRule declares the hooks of the PVCs it matches
+type HookRule struct {
+	Namespace    string   `json:"namespace,omitempty"`    // Namespace glob (empty matches all)
+	PVC          string   `json:"pvc,omitempty"`          // PVC name glob (empty matches all)
+	StorageClass string   `json:"storageClass,omitempty"` // StorageClass glob (empty matches all)
+	Actions      []string `json:"actions,omitempty"`      // expand, migrate (empty matches all)
+	Pre          []Hook   `json:"pre,omitempty"`
+	Post         []Hook   `json:"post,omitempty"`
+}
+
+// AnnotationHookPolicy decides which hooks PVC annotations may declare.
+// Anyone allowed to edit a PVC can set its annotations, so they may only run
+// commands in the listed containers and call the listed hosts.
+type AnnotationHookPolicy struct {
+	Enabled    bool     `json:"enabled"`
+	Namespaces []string `json:"namespaces,omitempty"` // namespace globs (empty matches all)
+	Containers []string `json:"containers,omitempty"` // container globs exec hooks may run in; they must name their container
+	URLHosts   []string `json:"urlHosts,omitempty"`   // host globs http hooks may call
+}
+
+// HookPolicy declares hooks around resize and migrate; the first matching rule applies
+type HookPolicy struct {
+	Rules       []HookRule           `json:"rules,omitempty"`
+	Annotations AnnotationHookPolicy `json:"annotations,omitempty"` // hooks from PVC annotations (refused unless enabled)
+}
+
+// HookPayload describes the operation to a hook; it is the body of HTTP hooks
+type HookPayload struct {
+	Stage        string `json:"stage"` // pre or post
+	Action       string `json:"action"`
+	Namespace    string `json:"namespace"`
+	PVC          string `json:"pvc"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+	Succeeded    *bool  `json:"succeeded,omitempty"` // post hooks only
+	Error        string `json:"error,omitempty"`
+}
+
+// DisplayName identifies a hook in messages
+func (h Hook) DisplayName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if h.Exec != nil {
+		return strings.TrimSpace("exec " + strings.Join(h.Exec.Command, " "))
+	}
+	if h.HTTP != nil {
+		return h.method() + " " + h.HTTP.URL
+	}
+	return "hook"
+}
+
+func (h Hook) method() string {
+	if h.HTTP != nil && h.HTTP.Method != "" {
+		return strings.ToUpper(h.HTTP.Method)
+	}
+	return http.MethodPost
+}
+
+// timeout returns the time the hook may run; it was validated on load
+func (h Hook) timeout() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultHookTimeout
+}
+
+func (h Hook) validate() error {
+	switch {
+	case (h.Exec == nil) == (h.HTTP == nil):
+		return fmt.Errorf("exactly one of exec and http must be set")
+	case h.Exec != nil && len(h.Exec.Command) == 0:
+		return fmt.Errorf("exec.command is empty")
+	case h.HTTP != nil:
+		u, err := url.Parse(h.HTTP.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid http.url %q", h.HTTP.URL)
+		}
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", h.Timeout)
+		}
+	}
+	if h.OnFailure != "" && h.OnFailure != HookAbort && h.OnFailure != HookContinue {
+		return fmt.Errorf("onFailure %q must be %s or %s", h.OnFailure, HookAbort, HookContinue)
+	}
+	return nil
+}
+
+func (r HookRule) validate() error {
+	for i, h := range append(append([]Hook{}, r.Pre...), r.Post...) {
+		if err := h.validate(); err != nil {
+			return fmt.Errorf("hook #%d (%s): %v", i+1, h.DisplayName(), err)
+		}
+	}
+	return nil
+}
+
+func (r HookRule) matches(pvc corev1.PersistentVolumeClaim, action string) bool {
+	if !globMatch(r.Namespace, pvc.Namespace) || !globMatch(r.PVC, pvc.Name) || !globMatch(r.StorageClass, claimStorageClass(pvc)) {
+		return false
+	}
+	if len(r.Actions) == 0 {
+		return true
+	}
+	for _, a := range r.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatchAny reports whether name matches one of the patterns
+func globMatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if globMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports why a hook declared by an annotation of a PVC in namespace
+// ns is not allowed, or empty when it is
+func (p AnnotationHookPolicy) allows(ns string, h Hook) string {
+	switch {
+	case !p.Enabled:
+		return "the policy does not accept hook annotations (hooks.annotations.enabled)"
+	case len(p.Namespaces) > 0 && !globMatchAny(p.Namespaces, ns):
+		return fmt.Sprintf("namespace %s is not in hooks.annotations.namespaces", ns)
+	case h.Exec != nil && h.Exec.Container == "":
+		return "exec hooks from annotations must name their container"
+	case h.Exec != nil && !globMatchAny(p.Containers, h.Exec.Container):
+		return fmt.Sprintf("container %s is not in hooks.annotations.containers", h.Exec.Container)
+	case h.HTTP != nil:
+		u, _ := url.Parse(h.HTTP.URL)
+		if !globMatchAny(p.URLHosts, u.Hostname()) {
+			return fmt.Sprintf("host %s is not in hooks.annotations.urlHosts", u.Hostname())
+		}
+	}
+	return ""
+}
+
+// annotationHooks parses a hook annotation of a PVC and checks its hooks
+// against the policy
+func (p HookPolicy) annotationHooks(pvc corev1.PersistentVolumeClaim, key string) ([]Hook, bool, error) {
+	value, ok := pvc.Annotations[key]
+	if !ok {
+		return nil, false, nil
+	}
+	var hooks []Hook
+	if err := yaml.Unmarshal([]byte(value), &hooks); err != nil {
+		return nil, true, fmt.Errorf("annotation %s: %v", key, err)
+	}
+	for i, h := range hooks {
+		if err := h.validate(); err != nil {
+			return nil, true, fmt.Errorf("annotation %s hook #%d (%s): %v", key, i+1, h.DisplayName(), err)
+		}
+		if reason := p.Annotations.allows(pvc.Namespace, h); reason != "" {
+			return nil, true, fmt.Errorf("annotation %s hook #%d (%s) refused: %s", key, i+1, h.DisplayName(), reason)
+		}
+	}
+	return hooks, true, nil
+}
+
+// HooksFor resolves the hooks of an operation on a PVC: the first matching
+// policy rule, with each stage replaced by the PVC annotation when present.
+// Invalid annotations, and annotations the policy does not allow, are an
+// error, so that an operation never runs without the hooks its owner declared.
+func (p HookPolicy) HooksFor(pvc corev1.PersistentVolumeClaim, action string) (pre, post []Hook, err error) {
+	for _, rule := range p.Rules {
+		if rule.matches(pvc, action) {
+			pre, post = rule.Pre, rule.Post
+			break
+		}
+	}
+	if hooks, ok, err := p.annotationHooks(pvc, AnnotationPreHooks); err != nil {
+		return nil, nil, err
+	} else if ok {
+		pre = hooks
+	}
+	if hooks, ok, err := p.annotationHooks(pvc, AnnotationPostHooks); err != nil {
+		return nil, nil, err
+	} else if ok {
+		post = hooks
+	}
+	return pre, post, nil
+}
+
+// runHook runs a single hook within its timeout and returns its output
+func runHook(h Hook, payload HookPayload) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout())
+	defer cancel()
+	var out string
+	var err error
+	if h.Exec != nil {
+		out, err = runExecHook(ctx, *h.Exec, payload)
+	} else {
+		out, err = runHTTPHook(ctx, h, payload)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("timed out after %s", h.timeout())
+	}
+	return strings.TrimSpace(out), err
+}
+
+// runExecHook waits for a running pod mounting the PVC — workloads may still
+// be starting after a migration — and runs the command in it
+func runExecHook(ctx context.Context, hook ExecHook, payload HookPayload) (string, error) {
+	for {
+		attachments, err := internal.FindPodAttachmentsForPVC(payload.Namespace, payload.PVC)
+		if err != nil {
+			return "", err
+		}
+		for _, a := range attachments {
+			if !a.Active() {
+				continue
+			}
+			container := hook.Container
+			if container == "" {
+				container = a.Container
+			}
+			return internal.ExecInContainer(ctx, payload.Namespace, a.PodName, container, hook.Command)
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("no running pod mounts PVC %s/%s", payload.Namespace, payload.PVC)
+		case <-time.After(resizePollInterval):
+		}
+	}
+}
+
+func runHTTPHook(ctx context.Context, h Hook, payload HookPayload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, h.method(), h.HTTP.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.HTTP.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return string(out), fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// Hooks runs the hooks declared for a PVC around an operation on it
+type Hooks struct {
+	policy HookPolicy
+}
+
+// NewHooks builds the hook runner of a policy
+func NewHooks(policy HookPolicy) *Hooks {
+	return &Hooks{policy: policy}
+}
+
+// Run runs the pre hooks of an operation, the operation, then its post hooks.
+// A failing pre hook aborts the operation unless it is marked continue. Post
+// hooks run whatever happened — e.g. to thaw a filesystem frozen by a pre
+// hook — and their failures are reported without changing the outcome.
+func (h *Hooks) Run(pvc corev1.PersistentVolumeClaim, payload HookPayload, logf Logf, run func() error) error {
+	pre, post, err := h.policy.HooksFor(pvc, payload.Action)
+	if err != nil {
+		return fmt.Errorf("hooks of PVC %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	payload.Namespace, payload.PVC = pvc.Namespace, pvc.Name
+	if payload.StorageClass == "" {
+		payload.StorageClass = claimStorageClass(pvc)
+	}
+
+	payload.Stage = HookStagePre
+	var opErr error
+	for _, hook := range pre {
+		out, err := runHook(hook, payload)
+		if err == nil {
+			logf("pre hook %s succeeded%s", hook.DisplayName(), hookOutput(out))
+			continue
+		}
+		if hook.OnFailure == HookContinue {
+			logf("⚠️  pre hook %s failed, continuing: %v", hook.DisplayName(), err)
+			continue
+		}
+		opErr = fmt.Errorf("pre hook %s failed, %s aborted: %v", hook.DisplayName(), payload.Action, err)
+		break
+	}
+	if opErr == nil {
+		opErr = run()
+	}
+
+	payload.Stage = HookStagePost
+	succeeded := opErr == nil
+	payload.Succeeded = &succeeded
+	if opErr != nil {
+		payload.Error = opErr.Error()
+	}
+	for _, hook := range post {
+		if out, err := runHook(hook, payload); err != nil {
+			logf("⚠️  post hook %s failed: %v", hook.DisplayName(), err)
+		} else {
+			logf("post hook %s succeeded%s", hook.DisplayName(), hookOutput(out))
+		}
+	}
+	return opErr
+}
+
+// hookOutput renders the first line of a hook's output for the logs
+func hookOutput(out string) string {
+	if out == "" {
+		return ""
+	}
+	line, _, _ := strings.Cut(out, "\n")
+	if len(line) > 120 {
+		line = line[:120] + "…"
+	}
+	return ": " + line
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHooksForAnnotations(t *testing.T) {
+	allowed := AnnotationHookPolicy{Enabled: true, Namespaces: []string{"db-*"}, Containers: []string{"postgres"}, URLHosts: []string{"ops.example.com"}}
+	policy := HookPolicy{Rules: []HookRule{{Pre: []Hook{{Name: "policy", HTTP: &HTTPHook{URL: "https://hooks.internal/pre"}}}}}}
+
+	tests := []struct {
+		name        string
+		annotations AnnotationHookPolicy
+		namespace   string
+		pre         string
+		want        string // name of the resolved pre hook
+		refusal     string
+	}{
+		{"no annotation", AnnotationHookPolicy{}, "db-main", "", "policy", ""},
+		{"not opted in", AnnotationHookPolicy{}, "db-main", `[{"name":"freeze","exec":{"container":"postgres","command":["fsfreeze","-f","/data"]}}]`, "", "does not accept hook annotations"},
+		{"allowed exec", allowed, "db-main", `[{"name":"freeze","exec":{"container":"postgres","command":["fsfreeze","-f","/data"]}}]`, "freeze", ""},
+		{"namespace not allowed", allowed, "web", `[{"name":"freeze","exec":{"container":"postgres","command":["true"]}}]`, "", "namespace web"},
+		{"exec without container", allowed, "db-main", `[{"name":"freeze","exec":{"command":["true"]}}]`, "", "must name their container"},
+		{"container not allowed", allowed, "db-main", `[{"name":"steal","exec":{"container":"vault","command":["cat","/secrets"]}}]`, "", "container vault"},
+		{"allowed host", allowed, "db-main", `[{"name":"notify","http":{"url":"https://ops.example.com/storage"}}]`, "notify", ""},
+		{"host not allowed", allowed, "db-main", `[{"name":"ssrf","http":{"url":"http://169.254.169.254/latest"}}]`, "", "host 169.254.169.254"},
+		{"invalid annotation", allowed, "db-main", `[{"name":"broken"}]`, "", "exactly one of exec and http"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			p.Annotations = tt.annotations
+			pvc := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: tt.namespace}}
+			if tt.pre != "" {
+				pvc.Annotations = map[string]string{AnnotationPreHooks: tt.pre}
+			}
+			pre, _, err := p.HooksFor(pvc, ActionExpand)
+			if tt.refusal != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.refusal) {
+					t.Fatalf("HooksFor() error = %v, want %q", err, tt.refusal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HooksFor() error = %v", err)
+			}
+			if len(pre) != 1 || pre[0].Name != tt.want {
+				t.Errorf("HooksFor() pre = %+v, want %s", pre, tt.want)
+			}
+		})
+	}
+}
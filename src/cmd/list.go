@@ -28,7 +28,7 @@ var listCmd = &cobra.Command{
 		// Create one table across all namespaces
 		t := table.NewWriter()
 		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"Namespace", "Name", "Allocated Storage"})
+		t.AppendHeader(table.Row{"Namespace", "Name", "Allocated Storage", "Notes"})
 
 		for _, ns := range namespaces {
 			pvcs, err := internal.ListPVCs(ns)
@@ -41,11 +41,21 @@ var listCmd = &cobra.Command{
 				continue
 			}
 
+			workloads, err := internal.ListWorkloads(ns)
+			if err != nil {
+				fmt.Printf("Error listing workloads in namespace %s: %v\n", ns, err)
+			}
+
 			for _, pvc := range pvcs {
+				notes := ""
+				if leftover, ok := workloads.ScaleDownLeftoverFor(pvc.Name); ok {
+					notes = "⚠️  " + leftover.String()
+				}
 				t.AppendRow(table.Row{
 					ns,
 					pvc.Name,
 					pvc.Status.Capacity.Storage().String(),
+					notes,
 				})
 			}
 		}
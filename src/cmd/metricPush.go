@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"pvc-audit/util"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -24,26 +25,27 @@ func PrintClusterReportCLI(report ClusterReport) {
 	fmt.Printf("PVCs without Wastage: %d\n", report.PVCsWithoutWastage)
 	fmt.Printf("Total Allocated (GB): %.2f\n", report.TotalAllocatedGB)
 	fmt.Printf("Total Used (GB): %.2f\n", report.TotalUsedGB)
-	fmt.Printf("Total Wasted (GB): %.2f (%.0f%%)\n\n",
+	fmt.Printf("Total Wasted (GB): %.2f (%.0f%%)\n",
 		report.TotalWastedGB,
 		float64(report.TotalWastedGB*100)/report.TotalAllocatedGB,
 	)
+	fmt.Printf("Reclaimable by Right-sizing (GB): %.2f\n\n", float64(report.TotalReclaimableMB)/1024)
 
 	// Namespace-wise details
 	for _, nsReport := range report.NamespaceReports {
 		fmt.Printf("\n🔹 Namespace: %s\n", nsReport.Namespace)
-		fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------")
-		fmt.Printf("%-25s %-10s %-15s %-15s %-10s %-15s %-12s %-20s\n",
-			"PVC NAME", "ATTACHED", "ALLOCATED", "USED", "USED(%)", "WASTED", "WASTAGE(%)", "CATEGORY")
-		fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------")
+		fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------------------------------------------------")
+		fmt.Printf("%-25s %-17s %-15s %-15s %-10s %-15s %-12s %-22s %-15s %-8s %-20s\n",
+			"PVC NAME", "ATTACHED", "ALLOCATED", "USED", "USED(%)", "WASTED", "WASTAGE(%)", "RECOMMENDED", "RECLAIMABLE", "AGE", "CATEGORY")
+		fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------------------------------------------------")
 
 		for _, pvc := range nsReport.PVCs {
 			// attached check
 			attached := "Yes"
-			for _, u := range report.UnattachedPVCs {
-				if u.Name == pvc.Name && u.Namespace == pvc.Namespace {
-					attached = "No"
-					break
+			if !pvc.Attached {
+				attached = "No"
+				if pvc.PodPhase != "" && pvc.PodPhase != "Unattached" {
+					attached = "No (" + pvc.PodPhase + ")"
 				}
 			}
 
@@ -55,7 +57,13 @@ func PrintClusterReportCLI(report ClusterReport) {
 
 			// determine category
 			category := "Healthy"
-			if attached == "No" {
+			if pvc.Suppressed {
+				category = "Suppressed"
+			} else if pvc.Category == CategoryNewlyProvisioned || pvc.Category == CategoryIdle || pvc.Category == CategoryAbandoned ||
+				pvc.Category == CategoryDormant || pvc.Category == CategoryOrphaned || pvc.Category == CategoryScaleDownLeft ||
+				pvc.Category == CategoryFillingFast {
+				category = pvc.Category
+			} else if !pvc.Attached {
 				category = "Unattached"
 			} else if pvc.WastagePct >= 80 {
 				category = "High Wastage"
@@ -72,8 +80,13 @@ func PrintClusterReportCLI(report ClusterReport) {
 			usedStr := fmt.Sprintf("%.2f %s", usedVal, usedUnit)
 			wastedStr := fmt.Sprintf("%.2f %s", wastedVal, wastedUnit)
 
+			recVal, recUnit := util.FormatSizeMBorGB(pvc.RecommendedMB)
+			reclaimVal, reclaimUnit := util.FormatSizeMBorGB(pvc.ReclaimableMB)
+			recStr := fmt.Sprintf("%.2f %s (%s)", recVal, recUnit, pvc.Confidence)
+			reclaimStr := fmt.Sprintf("%.2f %s", reclaimVal, reclaimUnit)
+
 			// print row
-			fmt.Printf("%-25s %-10s %-15s %-15s %-10.1f %-15s %-12d %-20s\n",
+			fmt.Printf("%-25s %-17s %-15s %-15s %-10.1f %-15s %-12d %-22s %-15s %-8s %-20s\n",
 				pvc.Name,
 				attached,
 				allocStr,
@@ -81,10 +94,23 @@ func PrintClusterReportCLI(report ClusterReport) {
 				usedPct,
 				wastedStr,
 				pvc.WastagePct,
+				recStr,
+				reclaimStr,
+				FormatAge(time.Since(pvc.CreatedAt)),
 				category,
 			)
 		}
 	}
+
+	fmt.Print(ForecastSummary(report))
+	fmt.Print(CostSummary(report))
+	fmt.Print(HealthSummary(report))
+	fmt.Print(ScaleDownLeftoverSummary(report))
+	fmt.Print(SnapshotSummaryReport(report))
+	fmt.Print(SuppressionSummary(report))
+	fmt.Print(BaselineSummary(report.Baseline))
+	fmt.Printf("\n📄 Detailed CSV Report: %s\n", report.CSVFilePath)
+	fmt.Printf("📄 JSON Report (baseline): %s\n", report.JSONFilePath)
 }
 
 func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
@@ -133,6 +159,66 @@ func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
 			Help:        "Number of PVCs eligible for cleanup",
 			ConstLabels: prometheus.Labels{"cluster": cluster},
 		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_suppressed",
+			Help:        "Number of PVCs with suppressed findings",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_pending",
+			Help:        "Number of PVCs in Pending phase",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_lost",
+			Help:        "Number of PVCs in Lost phase",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_snapshot_total_gb",
+			Help:        "Total restore size of VolumeSnapshots in GB",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_snapshots_past_retention",
+			Help:        "Number of VolumeSnapshots older than the retention",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_snapshot_contents_orphaned",
+			Help:        "Number of VolumeSnapshotContents without a VolumeSnapshot",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pv_flagged",
+			Help:        "Number of Released, Available, Failed or claim-less PVs",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pv_flagged_gb",
+			Help:        "Capacity held by flagged PVs in GB",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_total_monthly_cost",
+			Help:        "Monthly cost of all priced PVCs",
+			ConstLabels: prometheus.Labels{"cluster": cluster, "currency": clusterReport.Currency},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_total_wasted_cost",
+			Help:        "Monthly cost of unused PVC space",
+			ConstLabels: prometheus.Labels{"cluster": cluster, "currency": clusterReport.Currency},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_total_potential_savings",
+			Help:        "Monthly cost reclaimable by right-sizing PVCs",
+			ConstLabels: prometheus.Labels{"cluster": cluster, "currency": clusterReport.Currency},
+		}),
+		prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "pvc_filling_fast",
+			Help:        "Number of PVCs projected to be full soon",
+			ConstLabels: prometheus.Labels{"cluster": cluster},
+		}),
 	}
 
 	// Set cluster-level values
@@ -144,6 +230,51 @@ func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
 	pushCollector[5].(prometheus.Gauge).Set(float64(len(clusterReport.UnattachedPVCs)))
 	pushCollector[6].(prometheus.Gauge).Set(float64(len(clusterReport.NamespaceReports)))
 	pushCollector[7].(prometheus.Gauge).Set(float64(len(clusterReport.CleanupCandidates)))
+	pushCollector[8].(prometheus.Gauge).Set(float64(len(clusterReport.SuppressedPVCs)))
+	pushCollector[9].(prometheus.Gauge).Set(float64(clusterReport.PendingPVCs))
+	pushCollector[10].(prometheus.Gauge).Set(float64(clusterReport.LostPVCs))
+	pushCollector[11].(prometheus.Gauge).Set(float64(clusterReport.TotalSnapshotMB) / 1024)
+	pushCollector[12].(prometheus.Gauge).Set(float64(clusterReport.StaleSnapshots))
+	pushCollector[13].(prometheus.Gauge).Set(float64(len(clusterReport.OrphanedSnapshots)))
+	pushCollector[14].(prometheus.Gauge).Set(float64(len(clusterReport.PVFindings)))
+	pushCollector[15].(prometheus.Gauge).Set(float64(clusterReport.PVFindingsMB) / 1024)
+	pushCollector[16].(prometheus.Gauge).Set(clusterReport.TotalMonthlyCost)
+	pushCollector[17].(prometheus.Gauge).Set(clusterReport.TotalWastedCost)
+	pushCollector[18].(prometheus.Gauge).Set(clusterReport.TotalSavingsCost)
+	pushCollector[19].(prometheus.Gauge).Set(float64(len(clusterReport.FillingFastPVCs)))
+
+	// Per-PVC health (not Bound)
+	for _, h := range clusterReport.UnhealthyPVCs {
+		pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pvc_unbound_age_days",
+			Help: "Age in days of a PVC that is not Bound",
+			ConstLabels: prometheus.Labels{
+				"cluster":       cluster,
+				"namespace":     h.Namespace,
+				"pvc":           h.Name,
+				"phase":         h.Phase,
+				"storage_class": h.StorageClass,
+			},
+		}))
+		pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(float64(h.AgeDays))
+	}
+
+	// Per-PV findings
+	for _, pv := range clusterReport.PVFindings {
+		pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pv_flagged_capacity_gb",
+			Help: "Capacity of a flagged PV in GB",
+			ConstLabels: prometheus.Labels{
+				"cluster":        cluster,
+				"pv":             pv.Name,
+				"phase":          pv.Phase,
+				"reclaim_policy": pv.ReclaimPolicy,
+				"storage_class":  pv.StorageClass,
+				"finding":        pv.Finding,
+			},
+		}))
+		pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(float64(pv.CapacityMB) / 1024)
+	}
 
 	// Namespace-level metrics
 	for _, nsReport := range clusterReport.NamespaceReports {
@@ -155,7 +286,7 @@ func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
 			nsAllocatedGB += pvc.Allocated
 			nsUsedGB += pvc.Used
 			nsWastedGB += pvc.Wasted
-			if pvc.WastagePct >= 80 {
+			if pvc.WastagePct >= 80 && !pvc.Suppressed {
 				nsPVCsWithWastage++
 			}
 
@@ -207,6 +338,71 @@ func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
 				},
 			}))
 			pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(float64(pvc.WastagePct))
+
+			if pvc.Forecasted {
+				pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "pvc_growth_mb_per_day",
+					Help: "PVC growth rate in MB per day",
+					ConstLabels: prometheus.Labels{
+						"cluster":   cluster,
+						"namespace": ns,
+						"pvc":       pvc.Name,
+					},
+				}))
+				pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(pvc.GrowthMBPerDay)
+
+				if pvc.DaysUntilFull >= 0 {
+					pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+						Name: "pvc_days_until_full",
+						Help: "Projected days until the PVC is full",
+						ConstLabels: prometheus.Labels{
+							"cluster":   cluster,
+							"namespace": ns,
+							"pvc":       pvc.Name,
+						},
+					}))
+					pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(pvc.DaysUntilFull)
+				}
+			}
+
+			if pvc.CostSource != "" {
+				pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "pvc_monthly_cost",
+					Help: "PVC monthly cost",
+					ConstLabels: prometheus.Labels{
+						"cluster":   cluster,
+						"namespace": ns,
+						"pvc":       pvc.Name,
+						"currency":  clusterReport.Currency,
+					},
+				}))
+				pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(pvc.MonthlyCost)
+
+				pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "pvc_wasted_cost",
+					Help: "PVC monthly cost of unused space",
+					ConstLabels: prometheus.Labels{
+						"cluster":   cluster,
+						"namespace": ns,
+						"pvc":       pvc.Name,
+						"currency":  clusterReport.Currency,
+					},
+				}))
+				pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(pvc.WastedCost)
+			}
+
+			if pvc.SnapshotCount > 0 {
+				pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "pvc_snapshot_gb",
+					Help: "Restore size of the PVC's VolumeSnapshots in GB",
+					ConstLabels: prometheus.Labels{
+						"cluster":   cluster,
+						"namespace": ns,
+						"pvc":       pvc.Name,
+					},
+				}))
+				pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(float64(pvc.SnapshotMB) / 1024)
+			}
 		}
 
 		// Namespace aggregated metrics
@@ -239,6 +435,34 @@ func PushPVCMetrics(pushGateway string, clusterReport ClusterReport) error {
 		pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(float64(nsPVCsWithWastage))
 	}
 
+	// Namespace cost metrics
+	if clusterReport.Currency != "" {
+		for _, c := range NamespaceCosts(clusterReport) {
+			labels := prometheus.Labels{"cluster": cluster, "namespace": c.Namespace, "currency": clusterReport.Currency}
+
+			pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+				Name:        "pvc_namespace_monthly_cost",
+				Help:        "Namespace monthly PVC cost",
+				ConstLabels: labels,
+			}))
+			pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(c.MonthlyCost)
+
+			pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+				Name:        "pvc_namespace_wasted_cost",
+				Help:        "Namespace monthly cost of unused PVC space",
+				ConstLabels: labels,
+			}))
+			pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(c.WastedCost)
+
+			pushCollector = append(pushCollector, prometheus.NewGauge(prometheus.GaugeOpts{
+				Name:        "pvc_namespace_potential_savings",
+				Help:        "Namespace monthly cost reclaimable by right-sizing",
+				ConstLabels: labels,
+			}))
+			pushCollector[len(pushCollector)-1].(prometheus.Gauge).Set(c.SavingsCost)
+		}
+	}
+
 	// Push all metrics to Pushgateway
 	pusher := push.New(pushGateway, "pvc_audit_metrics")
 	for _, c := range pushCollector {
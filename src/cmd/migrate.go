@@ -0,0 +1,1010 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	migratePVC       string
+	migrateTo        string
+	migrateCopyImage string
+	migrateTimeout   time.Duration
+	migrateClass     string
+)
+
+// Annotations recording a migration on the PVs and the recreated claim
+const (
+	AnnotationMigratedTo            = "spacio.io/migrated-to"
+	AnnotationMigratedFrom          = "spacio.io/migrated-from"
+	AnnotationMigrationOf           = "spacio.io/migration-of"
+	AnnotationOriginalReclaimPolicy = "spacio.io/original-reclaim-policy"
+	AnnotationScaledWorkloads       = "spacio.io/scaled-workloads"
+	AnnotationSwapOf                = "spacio.io/swap-of"    // on the new PV while the swap runs: <namespace>/<pvc>
+	AnnotationSwapClaim             = "spacio.io/swap-claim" // on the new PV while the swap runs: the claim to recreate
+)
+
+const (
+	// migrateTempSuffix names the claim the data is copied to before the swap
+	migrateTempSuffix = "-spacio-tmp"
+	// migrateStepTimeout bounds the waits around the copy (pods stopping, binding, deletion)
+	migrateStepTimeout = 5 * time.Minute
+	// migrateVerifyPrefix marks the verification line in the copy Job logs
+	migrateVerifyPrefix = "SPACIO_VERIFY "
+	// migrateProgressInterval is how often the copied amount is reported
+	migrateProgressInterval = 30 * time.Second
+)
+
+// Phases reported while migrating a PVC
+const (
+	MigratePhaseValidated  = "Validated"
+	MigratePhaseScaledDown = "ScaledDown"
+	MigratePhaseCopying    = "Copying"
+	MigratePhaseVerified   = "Verified"
+	MigratePhaseSwapped    = "Swapped"
+	MigratePhaseCompleted  = "Completed"
+	MigratePhaseFailed     = "Failed"
+)
+
+// copyScript copies /src to /dst with rsync (cp -a when rsync cannot be
+// installed), then compares file counts and a checksum over every file
+const copyScript = `set -eu
+need=$(du -sk /src | cut -f1)
+avail=$(df -Pk /dst | awk 'NR==2 {print $4}')
+if [ "$need" -gt "$avail" ]; then
+  echo "source holds ${need}K but only ${avail}K are free on the new volume"
+  exit 2
+fi
+if command -v rsync >/dev/null 2>&1 || apk add --no-cache rsync >/dev/null 2>&1; then
+  rsync -aH --numeric-ids --delete /src/ /dst/
+else
+  echo "rsync unavailable, copying with cp -a"
+  cp -a /src/. /dst/
+fi
+count() { cd "$1" && find . -type f | wc -l; }
+sums() { cd "$1" && find . -type f -exec sha256sum {} + | sort -k2 | sha256sum | cut -d' ' -f1; }
+src_files=$(count /src); dst_files=$(count /dst)
+src_sum=$(sums /src); dst_sum=$(sums /dst)
+echo "SPACIO_VERIFY files=$src_files/$dst_files sha256=$src_sum/$dst_sum"
+[ "$src_files" = "$dst_files" ] && [ "$src_sum" = "$dst_sum" ]
+`
+
+// MigrateSpec describes a copy-and-swap migration of a PVC
+type MigrateSpec struct {
+	Namespace    string
+	PVC          string
+	Size         resource.Quantity // zero keeps the current size
+	StorageClass string            // empty keeps the current class
+	CopyImage    string
+	Timeout      time.Duration // how long the copy Job may run
+}
+
+// MigrateResult is the outcome of migrating a single PVC
+type MigrateResult struct {
+	Namespace    string
+	PVC          string
+	From         resource.Quantity
+	To           resource.Quantity
+	StorageClass string
+	OldPV        string // retained until released with `migrate release`
+	NewPV        string
+	Phase        string // last phase reached
+	Verification string // file count and checksum comparison of the copy Job
+	Duration     time.Duration
+}
+
+// scaledWorkload is a workload stopped for a migration, with the state to
+// restore. The list is recorded on the temporary claim so that an interrupted
+// migration can be resumed.
+type scaledWorkload struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas,omitempty"`
+}
+
+// claimStorageClass returns the class of a claim, including the legacy beta annotation
+func claimStorageClass(pvc corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return pvc.Annotations[corev1.BetaStorageClassAnnotation]
+}
+
+// ValidateMigration checks that a PVC can be copied to a new claim of the
+// target size and class: bound, filesystem mode, smaller than today unless the
+// class changes and, when usage was measured, leaving the configured headroom
+func ValidateMigration(pvc corev1.PersistentVolumeClaim, target resource.Quantity, storageClass string, usedMB int64) error {
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return fmt.Errorf("PVC %s/%s is %s, only bound claims can be migrated", pvc.Namespace, pvc.Name, pvc.Status.Phase)
+	}
+	if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return fmt.Errorf("PVC %s/%s is a raw block volume, only filesystem volumes can be copied", pvc.Namespace, pvc.Name)
+	}
+	if current := CurrentSize(pvc); storageClass == claimStorageClass(pvc) && target.Cmp(current) >= 0 {
+		return fmt.Errorf("target %s is not smaller than the current size %s and the storage class is unchanged, use resize to grow a PVC", target.String(), current.String())
+	}
+	if usedMB >= 0 {
+		needed := int64(float64(usedMB) * (1 + headroomPct/100))
+		if target.Value()/1024/1024 < needed {
+			return fmt.Errorf("%d MB are used, %s leaves less than %.0f%% headroom", usedMB, target.String(), headroomPct)
+		}
+	}
+	return nil
+}
+
+// workloadsToStop lists the workloads referencing the PVC that must be
+// stopped for a migration: Deployments, StatefulSets and standalone
+// ReplicaSets with replicas, and CronJobs not suspended. DaemonSets and
+// running Jobs cannot be paused and abort the migration.
+func workloadsToStop(pvc corev1.PersistentVolumeClaim) ([]scaledWorkload, error) {
+	workloads, err := internal.ListWorkloads(pvc.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var toStop []scaledWorkload
+	for _, ref := range workloads.ReferencesFor(pvc) {
+		switch {
+		case ref.Via == "ownerReference", workloads.IsControlled(ref.Kind, ref.Name):
+			continue
+		case ref.Kind == "DaemonSet":
+			return nil, fmt.Errorf("%s references the PVC and cannot be scaled down", ref.String())
+		case ref.Kind == "Job":
+			if ref.Replicas > 0 {
+				return nil, fmt.Errorf("%s is running, wait for it to finish", ref.String())
+			}
+		case ref.Kind == "CronJob":
+			if !ref.Suspended {
+				toStop = append(toStop, scaledWorkload{Kind: ref.Kind, Name: ref.Name})
+			}
+		default:
+			if ref.Replicas > 0 {
+				toStop = append(toStop, scaledWorkload{Kind: ref.Kind, Name: ref.Name, Replicas: ref.Replicas})
+			}
+		}
+	}
+	return toStop, nil
+}
+
+// stopWorkloads scales workloads to zero and suspends CronJobs. They are
+// recorded beforehand, so the caller restores them all on error.
+func stopWorkloads(ns string, toStop []scaledWorkload, logf Logf) error {
+	for _, w := range toStop {
+		if w.Kind == "CronJob" {
+			if err := internal.SetCronJobSuspend(ns, w.Name, true); err != nil {
+				return err
+			}
+			logf("%s: CronJob/%s suspended", MigratePhaseScaledDown, w.Name)
+			continue
+		}
+		if err := internal.ScaleWorkload(ns, w.Kind, w.Name, 0); err != nil {
+			return err
+		}
+		logf("%s: %s/%s scaled from %d to 0 replicas", MigratePhaseScaledDown, w.Kind, w.Name, w.Replicas)
+	}
+	return nil
+}
+
+// restoreWorkloads scales workloads back and resumes CronJobs, reporting errors
+// instead of stopping so that every workload gets its turn
+func restoreWorkloads(ns string, scaled []scaledWorkload, logf Logf) error {
+	var failed []string
+	for _, w := range scaled {
+		var err error
+		if w.Kind == "CronJob" {
+			err = internal.SetCronJobSuspend(ns, w.Name, false)
+		} else {
+			err = internal.ScaleWorkload(ns, w.Kind, w.Name, w.Replicas)
+		}
+		if err != nil {
+			logf("⚠️  %v", err)
+			failed = append(failed, w.Kind+"/"+w.Name)
+			continue
+		}
+		if w.Kind == "CronJob" {
+			logf("CronJob/%s resumed", w.Name)
+		} else {
+			logf("%s/%s scaled back to %d replicas", w.Kind, w.Name, w.Replicas)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not restore %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// waitFor polls cond until it holds, it fails or the timeout hits
+func waitFor(what string, timeout time.Duration, cond func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := cond()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for %s", timeout, what)
+		}
+		time.Sleep(resizePollInterval)
+	}
+}
+
+// copyJobName derives the copy Job name from the PVC; Job names become a
+// label value and must fit in 63 characters
+func copyJobName(pvcName string) string {
+	name := "spacio-copy-" + pvcName
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-.")
+	}
+	return name
+}
+
+// copyJob builds the Job copying the source claim (mounted read-only) to the destination claim
+func copyJob(ns, name, srcClaim, dstClaim, image string) *batchv1.Job {
+	backoff := int32(1)
+	labels := map[string]string{"app.kubernetes.io/managed-by": "spacio"}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   ns,
+			Labels:      labels,
+			Annotations: map[string]string{AnnotationMigrationOf: srcClaim},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoff,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "copy",
+						Image:   image,
+						Command: []string{"/bin/sh", "-c", copyScript},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "src", MountPath: "/src", ReadOnly: true},
+							{Name: "dst", MountPath: "/dst"},
+						},
+					}},
+					Volumes: []corev1.Volume{
+						{Name: "src", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: srcClaim, ReadOnly: true}}},
+						{Name: "dst", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: dstClaim}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// startCopyJob creates the copy Job, or picks up the Job of an interrupted
+// run: a running or completed Job is followed, a failed one is recreated and
+// rsync only transfers what is still missing
+func startCopyJob(job *batchv1.Job, logf Logf) error {
+	existing, err := internal.GetJob(job.Namespace, job.Name)
+	switch {
+	case err == nil && internal.JobCondition(*existing, batchv1.JobFailed) == nil:
+		logf("%s: following job %s/%s of the interrupted run", MigratePhaseCopying, job.Namespace, job.Name)
+		return nil
+	case err == nil:
+		logf("%s: job %s/%s failed earlier, restarting it", MigratePhaseCopying, job.Namespace, job.Name)
+		if err := internal.DeleteJob(job.Namespace, job.Name); err != nil {
+			return fmt.Errorf("deleting copy job %s/%s: %v", job.Namespace, job.Name, err)
+		}
+		err = waitFor("job "+job.Name+" to be deleted", migrateStepTimeout, func() (bool, error) {
+			_, err := internal.GetJob(job.Namespace, job.Name)
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return err
+		}
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("getting copy job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+
+	if _, err := internal.CreateJob(job); err != nil {
+		return fmt.Errorf("creating copy job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+	logf("%s: job %s/%s started with %s", MigratePhaseCopying, job.Namespace, job.Name, job.Spec.Template.Spec.Containers[0].Image)
+	return nil
+}
+
+// reportCopyProgress logs how much of the source has reached the destination,
+// measured with du in the running copy pod
+func reportCopyProgress(ns, jobName string, logf Logf) {
+	pod, err := internal.RunningJobPod(ns, jobName)
+	if err != nil {
+		return
+	}
+	clientset, config, err := internal.GetK8sClientWithConfig()
+	if err != nil {
+		return
+	}
+	out, err := internal.ExecCommandInPod(clientset, config, pod, ns, []string{"du", "-sk", "/src", "/dst"})
+	if err != nil {
+		return
+	}
+	sizes := map[string]int64{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			kb, _ := strconv.ParseInt(fields[0], 10, 64)
+			sizes[fields[1]] = kb
+		}
+	}
+	if sizes["/src"] == 0 {
+		return
+	}
+	copiedVal, copiedUnit := util.FormatSizeMBorGB(sizes["/dst"] / 1024)
+	totalVal, totalUnit := util.FormatSizeMBorGB(sizes["/src"] / 1024)
+	logf("%s: %.2f %s of %.2f %s copied (%d%%)", MigratePhaseCopying, copiedVal, copiedUnit, totalVal, totalUnit, sizes["/dst"]*100/sizes["/src"])
+}
+
+// runCopyJob runs the copy Job to completion, reporting progress, and returns
+// its verification line
+func runCopyJob(job *batchv1.Job, timeout time.Duration, logf Logf) (string, error) {
+	if err := startCopyJob(job, logf); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	lastProgress := start
+	var current *batchv1.Job
+	err := waitFor("job "+job.Name, timeout, func() (bool, error) {
+		var err error
+		current, err = internal.GetJob(job.Namespace, job.Name)
+		if err != nil {
+			return false, err
+		}
+		if internal.JobCondition(*current, batchv1.JobComplete) != nil || internal.JobCondition(*current, batchv1.JobFailed) != nil {
+			return true, nil
+		}
+		if time.Since(lastProgress) >= migrateProgressInterval {
+			lastProgress = time.Now()
+			reportCopyProgress(job.Namespace, job.Name, logf)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	logs, logErr := internal.JobLogs(job.Namespace, job.Name)
+	verification := ""
+	for _, line := range strings.Split(logs, "\n") {
+		if strings.HasPrefix(line, migrateVerifyPrefix) {
+			verification = strings.TrimPrefix(line, migrateVerifyPrefix)
+		}
+	}
+	if c := internal.JobCondition(*current, batchv1.JobFailed); c != nil {
+		if logErr == nil {
+			logf("copy job output:\n%s", strings.TrimSpace(logs))
+		}
+		if verification != "" {
+			return verification, fmt.Errorf("copy verification failed: %s", verification)
+		}
+		return "", fmt.Errorf("copy job failed: %s", c.Message)
+	}
+	if verification == "" {
+		return "", fmt.Errorf("copy job completed without a verification line (logs: %v)", logErr)
+	}
+	logf("%s: data copied in %s, %s", MigratePhaseVerified, time.Since(start).Round(time.Second), verification)
+	return verification, nil
+}
+
+// claimAnnotations copies the annotations of a claim, minus the ones the
+// binding and provisioning controllers own
+func claimAnnotations(annotations map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "pv.kubernetes.io/") || strings.HasPrefix(k, "volume.kubernetes.io/") || strings.HasPrefix(k, "volume.beta.kubernetes.io/") {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// swapState is what a swap needs once the temporary claim is gone; it is
+// recorded on the new PV, so that a rerun completes an interrupted swap
+type swapState struct {
+	Claim  corev1.PersistentVolumeClaim         // claim to recreate under the original name
+	Scaled []scaledWorkload                     // workloads to restore
+	Policy corev1.PersistentVolumeReclaimPolicy // reclaim policy of the new PV to restore
+}
+
+func readSwapState(pv corev1.PersistentVolume) (swapState, error) {
+	var state swapState
+	if err := json.Unmarshal([]byte(pv.Annotations[AnnotationSwapClaim]), &state.Claim); err != nil {
+		return state, fmt.Errorf("reading %s of PV %s: %v", AnnotationSwapClaim, pv.Name, err)
+	}
+	if recorded := pv.Annotations[AnnotationScaledWorkloads]; recorded != "" {
+		if err := json.Unmarshal([]byte(recorded), &state.Scaled); err != nil {
+			return state, fmt.Errorf("reading %s of PV %s: %v", AnnotationScaledWorkloads, pv.Name, err)
+		}
+	}
+	state.Policy = corev1.PersistentVolumeReclaimPolicy(pv.Annotations[AnnotationOriginalReclaimPolicy])
+	return state, nil
+}
+
+// findSwap returns the new PV of an interrupted swap of a PVC, if any
+func findSwap(ns, pvcName string) (*corev1.PersistentVolume, error) {
+	pvs, err := internal.ListPVs()
+	if err != nil {
+		return nil, err
+	}
+	for i, pv := range pvs {
+		if pv.Annotations[AnnotationSwapOf] == ns+"/"+pvcName {
+			return &pvs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// migrationClaim returns the PVC to migrate, or the claim recorded by an
+// interrupted swap that already deleted it
+func migrationClaim(ns, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+	pvc, err := internal.GetPVC(ns, pvcName)
+	if !apierrors.IsNotFound(err) {
+		return pvc, err
+	}
+	pv, swapErr := findSwap(ns, pvcName)
+	if swapErr != nil || pv == nil {
+		return nil, err
+	}
+	state, err := readSwapState(*pv)
+	if err != nil {
+		return nil, err
+	}
+	return &state.Claim, nil
+}
+
+// prepareSwap retains the new PV of the temporary claim and records on it
+// the claim to recreate under the original name — labels, annotations, owner
+// references and access modes preserved —, the workloads to restore and its
+// reclaim policy
+func prepareSwap(orig corev1.PersistentVolumeClaim, tmpName string, size resource.Quantity, scaled []scaledWorkload) (string, error) {
+	ns := orig.Namespace
+	tmp, err := internal.GetPVC(ns, tmpName)
+	if err != nil {
+		return "", fmt.Errorf("getting PVC %s/%s: %v", ns, tmpName, err)
+	}
+	newPV := tmp.Spec.VolumeName
+	pv, err := internal.GetPV(newPV)
+	if err != nil {
+		return "", fmt.Errorf("getting PV %s: %v", newPV, err)
+	}
+
+	annotations := claimAnnotations(orig.Annotations)
+	annotations[AnnotationMigratedFrom] = orig.Spec.VolumeName
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            orig.Name,
+			Namespace:       ns,
+			Labels:          orig.Labels,
+			Annotations:     annotations,
+			OwnerReferences: orig.OwnerReferences,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      orig.Spec.AccessModes,
+			StorageClassName: tmp.Spec.StorageClassName,
+			VolumeMode:       orig.Spec.VolumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			VolumeName: newPV,
+		},
+	}
+	recordedClaim, err := json.Marshal(claim)
+	if err != nil {
+		return newPV, err
+	}
+	recordedWorkloads, err := json.Marshal(scaled)
+	if err != nil {
+		return newPV, err
+	}
+	_, err = internal.SetPVReclaimPolicy(newPV, corev1.PersistentVolumeReclaimRetain, map[string]interface{}{
+		AnnotationSwapOf:                ns + "/" + orig.Name,
+		AnnotationSwapClaim:             string(recordedClaim),
+		AnnotationScaledWorkloads:       string(recordedWorkloads),
+		AnnotationOriginalReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+	})
+	if err != nil {
+		return newPV, fmt.Errorf("retaining PV %s: %v", newPV, err)
+	}
+	return newPV, nil
+}
+
+// completeSwap moves the new PV under the original claim name, from the state
+// prepareSwap recorded on it: the temporary claim is deleted, the original
+// claim is deleted (its PV is retained), and the claim is recreated bound to
+// the new PV. Steps already done are skipped, so that a rerun completes an
+// interrupted swap.
+func completeSwap(newPV string, logf Logf) (swapState, error) {
+	pv, err := internal.GetPV(newPV)
+	if err != nil {
+		return swapState{}, fmt.Errorf("getting PV %s: %v", newPV, err)
+	}
+	state, err := readSwapState(*pv)
+	if err != nil {
+		return state, err
+	}
+	ns, name := state.Claim.Namespace, state.Claim.Name
+	oldPV := state.Claim.Annotations[AnnotationMigratedFrom]
+	tmpName := name + migrateTempSuffix
+
+	tmp, err := internal.GetPVC(ns, tmpName)
+	switch {
+	case err == nil && tmp.Spec.VolumeName == newPV:
+		if err := internal.DeletePVC(ns, tmpName); err != nil {
+			return state, fmt.Errorf("deleting PVC %s/%s: %v", ns, tmpName, err)
+		}
+		err = waitFor("PV "+newPV+" to be released", migrateStepTimeout, func() (bool, error) {
+			pv, err := internal.GetPV(newPV)
+			return err == nil && pv.Status.Phase == corev1.VolumeReleased, err
+		})
+		if err != nil {
+			return state, err
+		}
+	case err != nil && !apierrors.IsNotFound(err):
+		return state, fmt.Errorf("getting PVC %s/%s: %v", ns, tmpName, err)
+	}
+
+	current, err := internal.GetPVC(ns, name)
+	switch {
+	case err == nil && current.Spec.VolumeName == newPV:
+		// recreated by the interrupted run
+	case err == nil && current.Spec.VolumeName != oldPV:
+		return state, fmt.Errorf("PVC %s/%s is bound to PV %s, neither the migrated PV %s nor its copy %s", ns, name, current.Spec.VolumeName, oldPV, newPV)
+	case err == nil:
+		if err := internal.DeletePVCUnchanged(*current); err != nil {
+			return state, fmt.Errorf("deleting PVC %s/%s: %v", ns, name, err)
+		}
+		err = waitFor("PVC "+name+" to be deleted", migrateStepTimeout, func() (bool, error) {
+			_, err := internal.GetPVC(ns, name)
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return state, err
+		}
+		logf("%s: original claim deleted, PV %s retained", MigratePhaseSwapped, oldPV)
+		fallthrough
+	case apierrors.IsNotFound(err):
+		if _, err := internal.ReservePVForClaim(newPV, ns, name); err != nil {
+			return state, fmt.Errorf("reserving PV %s for %s/%s: %v", newPV, ns, name, err)
+		}
+		claim := state.Claim.DeepCopy()
+		if _, err := internal.CreatePVC(claim); err != nil && !apierrors.IsAlreadyExists(err) {
+			return state, fmt.Errorf("recreating PVC %s/%s: %v", ns, name, err)
+		}
+	default:
+		return state, fmt.Errorf("getting PVC %s/%s: %v", ns, name, err)
+	}
+	err = waitFor("PVC "+name+" to bind", migrateStepTimeout, func() (bool, error) {
+		pvc, err := internal.GetPVC(ns, name)
+		return err == nil && pvc.Status.Phase == corev1.ClaimBound, err
+	})
+	if err != nil {
+		return state, err
+	}
+
+	// the new volume behaves like a freshly provisioned one again
+	_, err = internal.SetPVReclaimPolicy(newPV, state.Policy, map[string]interface{}{
+		AnnotationSwapOf:                nil,
+		AnnotationSwapClaim:             nil,
+		AnnotationScaledWorkloads:       nil,
+		AnnotationOriginalReclaimPolicy: nil,
+	})
+	if err != nil {
+		logf("⚠️  restoring reclaim policy %s on PV %s: %v", state.Policy, newPV, err)
+	}
+	logf("%s: PVC %s/%s recreated on PV %s", MigratePhaseSwapped, ns, name, newPV)
+	return state, nil
+}
+
+// finishSwap completes the swap onto newPV, marks the old PV and scales the
+// workloads back
+func finishSwap(result *MigrateResult, newPV string, logf Logf) error {
+	result.NewPV = newPV
+	state, err := completeSwap(newPV, logf)
+	if err != nil {
+		result.Phase = MigratePhaseFailed
+		return changeMade(fmt.Errorf("%v — workloads stay scaled down; the data is on PV %s (copy) and PV %s (original), both retained; rerun to complete the swap", err, newPV, displayOrDash(result.OldPV)))
+	}
+	result.Phase = MigratePhaseSwapped
+	internal.PatchPV(result.OldPV, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{AnnotationMigratedTo: result.Namespace + "/" + result.PVC + " (" + newPV + ")"},
+		},
+	})
+	if err := restoreWorkloads(result.Namespace, state.Scaled, logf); err != nil {
+		return changeMade(err)
+	}
+	result.Phase = MigratePhaseCompleted
+	return nil
+}
+
+// MigratePVC copies a PVC to a new claim of the target size and class and
+// swaps it in under the original name. The referencing workloads are stopped
+// during the copy and restored afterwards. A rerun after an interruption
+// resumes from the temporary claim and copy Job left behind. Failures before
+// the swap restore the workloads and keep the temporary claim for a resume;
+// the old PV is always retained and annotated with spacio.io/migrated-to until
+// it is released with `migrate release`.
+func MigratePVC(spec MigrateSpec, logf Logf) (MigrateResult, error) {
+	start := time.Now()
+	ns := spec.Namespace
+	result := MigrateResult{Namespace: ns, PVC: spec.PVC}
+
+	// a swap interrupted after the temporary claim was deleted is completed
+	// from the state recorded on the new PV
+	swapPV, err := findSwap(ns, spec.PVC)
+	if err != nil {
+		result.Phase = MigratePhaseFailed
+		return result, fmt.Errorf("looking for an interrupted swap of %s/%s: %v", ns, spec.PVC, err)
+	}
+	if swapPV != nil {
+		state, err := readSwapState(*swapPV)
+		if err != nil {
+			result.Phase = MigratePhaseFailed
+			return result, err
+		}
+		result.OldPV = state.Claim.Annotations[AnnotationMigratedFrom]
+		result.To = *state.Claim.Spec.Resources.Requests.Storage()
+		result.StorageClass = claimStorageClass(state.Claim)
+		if old, err := internal.GetPV(result.OldPV); err == nil {
+			result.From = *old.Spec.Capacity.Storage()
+		}
+		logf("%s: completing the interrupted swap onto PV %s", MigratePhaseVerified, swapPV.Name)
+		err = finishSwap(&result, swapPV.Name, logf)
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	pvc, err := internal.GetPVC(ns, spec.PVC)
+	if err != nil {
+		return result, fmt.Errorf("getting PVC %s/%s: %v", ns, spec.PVC, err)
+	}
+	result.From = CurrentSize(*pvc)
+	result.OldPV = pvc.Spec.VolumeName
+	if spec.Size.IsZero() {
+		spec.Size = result.From
+	}
+	result.To = spec.Size
+	result.StorageClass = claimStorageClass(*pvc)
+	if spec.StorageClass != "" {
+		if _, err := internal.GetStorageClass(spec.StorageClass); err != nil {
+			result.Phase = MigratePhaseFailed
+			return result, fmt.Errorf("getting storage class %s: %v", spec.StorageClass, err)
+		}
+		result.StorageClass = spec.StorageClass
+	}
+
+	usedMB := int64(-1)
+	if used, err := measureUsedBytes(ns, spec.PVC); err == nil {
+		usedMB = used / (1024 * 1024)
+	} else {
+		logf("usage not measured (%v), the copy job checks the free space", err)
+	}
+	if err := ValidateMigration(*pvc, spec.Size, result.StorageClass, usedMB); err != nil {
+		result.Phase = MigratePhaseFailed
+		return result, err
+	}
+
+	// a temporary claim left by an interrupted run is resumed when it matches
+	tmpName := spec.PVC + migrateTempSuffix
+	var scaled []scaledWorkload
+	tmp, err := internal.GetPVC(ns, tmpName)
+	switch {
+	case err == nil:
+		if tmp.Annotations[AnnotationMigrationOf] != spec.PVC {
+			result.Phase = MigratePhaseFailed
+			return result, fmt.Errorf("PVC %s/%s exists and was not created by a migration of %s", ns, tmpName, spec.PVC)
+		}
+		if claimStorageClass(*tmp) != result.StorageClass || tmp.Spec.Resources.Requests.Storage().Cmp(spec.Size) != 0 {
+			result.Phase = MigratePhaseFailed
+			return result, fmt.Errorf("PVC %s/%s is a migration to %s in %s, delete it to start over with another target",
+				ns, tmpName, tmp.Spec.Resources.Requests.Storage().String(), displayOrDash(claimStorageClass(*tmp)))
+		}
+		if recorded := tmp.Annotations[AnnotationScaledWorkloads]; recorded != "" {
+			if err := json.Unmarshal([]byte(recorded), &scaled); err != nil {
+				result.Phase = MigratePhaseFailed
+				return result, fmt.Errorf("reading %s of PVC %s/%s: %v", AnnotationScaledWorkloads, ns, tmpName, err)
+			}
+		}
+		logf("%s: resuming the migration into %s", MigratePhaseValidated, tmpName)
+	case apierrors.IsNotFound(err):
+		tmp = nil
+	default:
+		result.Phase = MigratePhaseFailed
+		return result, fmt.Errorf("getting PVC %s/%s: %v", ns, tmpName, err)
+	}
+	result.Phase = MigratePhaseValidated
+	logf("%s: %s (%s) → %s (%s), %s used", result.Phase, result.From.String(), displayOrDash(claimStorageClass(*pvc)),
+		spec.Size.String(), displayOrDash(result.StorageClass), formatUsedMB(usedMB))
+
+	// keep the old volume whatever happens to its claim; a resumed run finds
+	// the original policy in the annotation
+	oldPV, err := internal.GetPV(result.OldPV)
+	if err != nil {
+		result.Phase = MigratePhaseFailed
+		return result, fmt.Errorf("getting PV %s: %v", result.OldPV, err)
+	}
+	oldPolicy := oldPV.Spec.PersistentVolumeReclaimPolicy
+	if original, ok := oldPV.Annotations[AnnotationOriginalReclaimPolicy]; ok {
+		oldPolicy = corev1.PersistentVolumeReclaimPolicy(original)
+	}
+	_, err = internal.SetPVReclaimPolicy(result.OldPV, corev1.PersistentVolumeReclaimRetain, map[string]interface{}{
+		AnnotationOriginalReclaimPolicy: string(oldPolicy),
+	})
+	if err != nil {
+		result.Phase = MigratePhaseFailed
+		return result, fmt.Errorf("retaining PV %s: %v", result.OldPV, err)
+	}
+
+	rollback := func(err error) (MigrateResult, error) {
+		result.Phase = MigratePhaseFailed
+		restoreWorkloads(ns, scaled, logf)
+		internal.PatchPVCAnnotations(ns, tmpName, map[string]interface{}{AnnotationScaledWorkloads: nil})
+		internal.SetPVReclaimPolicy(result.OldPV, oldPolicy, map[string]interface{}{AnnotationOriginalReclaimPolicy: nil})
+		result.Duration = time.Since(start)
+		if tmp == nil {
+			return result, fmt.Errorf("%v (rolled back)", err)
+		}
+		return result, fmt.Errorf("%v (workloads restored; rerun to resume the copy into %s, or delete it to start over)", err, tmpName)
+	}
+
+	if tmp == nil {
+		tmp = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        tmpName,
+				Namespace:   ns,
+				Labels:      map[string]string{"app.kubernetes.io/managed-by": "spacio"},
+				Annotations: map[string]string{AnnotationMigrationOf: spec.PVC},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      pvc.Spec.AccessModes,
+				StorageClassName: &result.StorageClass,
+				VolumeMode:       pvc.Spec.VolumeMode,
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: spec.Size},
+				},
+			},
+		}
+		if _, err := internal.CreatePVC(tmp); err != nil {
+			tmp = nil
+			return rollback(fmt.Errorf("creating PVC %s/%s: %v", ns, tmpName, err))
+		}
+	}
+
+	// the workloads are recorded before they are stopped, so that a run
+	// interrupted while stopping them still restores them all
+	toStop, err := workloadsToStop(*pvc)
+	if err != nil {
+		return rollback(err)
+	}
+	if len(toStop) > 0 {
+		recorded, _ := json.Marshal(append(append([]scaledWorkload{}, scaled...), toStop...))
+		if err := internal.PatchPVCAnnotations(ns, tmpName, map[string]interface{}{AnnotationScaledWorkloads: string(recorded)}); err != nil {
+			return rollback(fmt.Errorf("recording scaled workloads on PVC %s/%s: %v", ns, tmpName, err))
+		}
+		scaled = append(scaled, toStop...)
+	}
+	if err := stopWorkloads(ns, toStop, logf); err != nil {
+		return rollback(err)
+	}
+	err = waitFor("pods using "+spec.PVC+" to stop", migrateStepTimeout, func() (bool, error) {
+		pods, err := internal.PodsHoldingPVC(ns, spec.PVC)
+		return len(pods) == 0, err
+	})
+	if err != nil {
+		if pods, _ := internal.PodsHoldingPVC(ns, spec.PVC); len(pods) > 0 {
+			err = fmt.Errorf("%v: pod %s still uses the PVC", err, pods[0])
+		}
+		return rollback(err)
+	}
+	result.Phase = MigratePhaseCopying
+	jobName := copyJobName(spec.PVC)
+	verification, err := runCopyJob(copyJob(ns, jobName, spec.PVC, tmpName, spec.CopyImage), spec.Timeout, logf)
+	result.Verification = verification
+	if err != nil {
+		return rollback(err)
+	}
+	internal.DeleteJob(ns, jobName)
+	result.Phase = MigratePhaseVerified
+
+	// from here on the original claim goes away; failures leave both PVs
+	// retained and the new one annotated, so that a rerun completes the swap
+	newPV, err := prepareSwap(*pvc, tmpName, spec.Size, scaled)
+	if err != nil {
+		return rollback(err)
+	}
+	err = finishSwap(&result, newPV, logf)
+	result.Duration = time.Since(start)
+	return result, err
+}
+
+func formatUsedMB(usedMB int64) string {
+	if usedMB < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d MB", usedMB)
+}
+
+// ReleaseMigratedPV releases the old PV of a finished migration: the original
+// reclaim policy is restored, so a Delete policy removes the backend volume;
+// a PV that was Retain already has only its object deleted
+func ReleaseMigratedPV(name string) (string, error) {
+	pv, err := internal.GetPV(name)
+	if err != nil {
+		return "", fmt.Errorf("getting PV %s: %v", name, err)
+	}
+	migratedTo, ok := pv.Annotations[AnnotationMigratedTo]
+	if !ok {
+		return "", fmt.Errorf("PV %s was not left behind by a migration (no %s annotation)", name, AnnotationMigratedTo)
+	}
+	if pv.Status.Phase == corev1.VolumeBound {
+		return "", fmt.Errorf("PV %s is bound again, not releasing it", name)
+	}
+
+	policy := corev1.PersistentVolumeReclaimPolicy(pv.Annotations[AnnotationOriginalReclaimPolicy])
+	if policy == corev1.PersistentVolumeReclaimDelete {
+		if _, err := internal.SetPVReclaimPolicy(name, policy, nil); err != nil {
+			return "", fmt.Errorf("setting reclaim policy of PV %s: %v", name, err)
+		}
+		return fmt.Sprintf("PV %s (migrated to %s) set to Delete, the provisioner removes the volume", name, migratedTo), nil
+	}
+	if err := internal.DeletePV(name); err != nil {
+		return "", fmt.Errorf("deleting PV %s: %v", name, err)
+	}
+	driver, handle := internal.PVSource(*pv)
+	return fmt.Sprintf("PV %s (migrated to %s) deleted; the backend volume %s %s was retained and must be removed by hand", name, migratedTo, driver, handle), nil
+}
+
+// migrateChange describes a migration for the guard: the storage reclaimed by
+// a shrink, or the whole volume when it moves to another class
+func migrateChange(pvc corev1.PersistentVolumeClaim, spec MigrateSpec) Change {
+	current := CurrentSize(pvc)
+	change := Change{Namespace: spec.Namespace, Name: spec.PVC, Action: ActionMigrate, Bytes: current.Value()}
+	if spec.StorageClass == "" || spec.StorageClass == claimStorageClass(pvc) {
+		change.Bytes = current.Value() - spec.Size.Value()
+	} else if !spec.Size.IsZero() {
+		change.Bytes = spec.Size.Value()
+	}
+	return change
+}
+
+// migratePayload describes a migration to its hooks
+func migratePayload(pvc corev1.PersistentVolumeClaim, spec MigrateSpec) HookPayload {
+	current := CurrentSize(pvc)
+	payload := HookPayload{Action: ActionMigrate, From: current.String(), To: current.String(), StorageClass: spec.StorageClass}
+	if !spec.Size.IsZero() {
+		payload.To = spec.Size.String()
+	}
+	return payload
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Shrink a PVC or move it to another StorageClass by copying its data to a new claim swapped in under the original name",
+	Example: `  spacio migrate -n db -p data-postgres-0 --to 20Gi
+  spacio migrate -n db -p data-postgres-0 --storage-class gp3
+  spacio migrate release pvc-0b5c9e4e-8d0f-4f7e-9d1a-3c2f6a0e1b77`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if migrateTo == "" && migrateClass == "" {
+			return fmt.Errorf("--to or --storage-class is required")
+		}
+		var target resource.Quantity
+		if migrateTo != "" {
+			var err error
+			if target, err = resource.ParseQuantity(migrateTo); err != nil {
+				return fmt.Errorf("invalid --to %q: %v", migrateTo, err)
+			}
+		}
+
+		guard, hooks, err := newCommandGuard()
+		if err != nil {
+			return err
+		}
+		pvc, err := migrationClaim(namespace, migratePVC)
+		if err != nil {
+			return fmt.Errorf("getting PVC %s/%s: %v", namespace, migratePVC, err)
+		}
+		logf := func(format string, args ...interface{}) {
+			fmt.Printf("  ↳ "+format+"\n", args...)
+		}
+		spec := MigrateSpec{
+			Namespace:    namespace,
+			PVC:          migratePVC,
+			Size:         target,
+			StorageClass: migrateClass,
+			CopyImage:    migrateCopyImage,
+			Timeout:      migrateTimeout,
+		}
+
+		fmt.Printf("🚚 Migrating PVC %s/%s\n", namespace, migratePVC)
+		var result MigrateResult
+		err = guard.Run(migrateChange(*pvc, spec), logf, func() error {
+			return hooks.Run(*pvc, migratePayload(*pvc, spec), logf, func() error {
+				var err error
+				result, err = MigratePVC(spec, logf)
+				return err
+			})
+		})
+		if err != nil {
+			fmt.Printf("❌ %s\n", phaseError(result.Phase, err))
+			return err
+		}
+		fmt.Printf("✅ PVC %s/%s migrated from %s to %s in class %s on PV %s in %s\n", namespace, migratePVC,
+			result.From.String(), result.To.String(), displayOrDash(result.StorageClass), result.NewPV, result.Duration.Round(time.Second))
+		fmt.Printf("📦 The old PV %s is retained; once the workload is verified run: spacio migrate release %s\n", result.OldPV, result.OldPV)
+		return nil
+	},
+}
+
+var migrateReleaseCmd = &cobra.Command{
+	Use:   "release <pv>",
+	Short: "Release the old PV retained by a migration, restoring its original reclaim policy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guard, _, err := newCommandGuard()
+		if err != nil {
+			return err
+		}
+		pv, err := internal.GetPV(args[0])
+		if err != nil {
+			return fmt.Errorf("getting PV %s: %v", args[0], err)
+		}
+		change := Change{Name: pv.Name, Action: "release", Bytes: pv.Spec.Capacity.Storage().Value()}
+		if pv.Spec.ClaimRef != nil {
+			change.Namespace = pv.Spec.ClaimRef.Namespace
+		}
+		var msg string
+		err = guard.Run(change, func(format string, args ...interface{}) {
+			fmt.Printf("  ↳ "+format+"\n", args...)
+		}, func() error {
+			var err error
+			msg, err = ReleaseMigratedPV(args[0])
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  %s\n", msg)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateReleaseCmd)
+	migrateCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	migrateCmd.Flags().StringVarP(&migratePVC, "pvc", "p", "", "PVC name")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target size (e.g. 20Gi, default the current size)")
+	migrateCmd.Flags().StringVar(&migrateClass, "storage-class", "", "Target StorageClass (default the current class)")
+	migrateCmd.Flags().StringVar(&migrateCopyImage, "copy-image", "alpine:3.20", "Image of the copy job (needs sh, find and sha256sum; rsync is installed with apk when missing)")
+	migrateCmd.Flags().DurationVar(&migrateTimeout, "timeout", time.Hour, "How long the copy job may run")
+	migrateCmd.Flags().Float64Var(&headroomPct, "headroom", 20, "Headroom the target must leave above the current usage (%)")
+	addGuardFlags(migrateCmd)
+	addGuardFlags(migrateReleaseCmd)
+	migrateCmd.MarkFlagRequired("pvc")
+}
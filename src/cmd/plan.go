@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	planOutput       string
+	planMinReclaimMB int64
+	planHorizonDays  int
+)
+
+// planVersion is bumped when the plan file format changes incompatibly
+const planVersion = 1
+
+// Plan actions
+const (
+	ActionExpand  = "expand"
+	ActionMigrate = "migrate"
+	ActionDelete  = "delete"
+	ActionSkip    = "skip"
+)
+
+// PlanItem is the planned change of a single PVC
+type PlanItem struct {
+	Namespace       string  `json:"namespace"`
+	PVC             string  `json:"pvc"`
+	UID             string  `json:"uid"`
+	ResourceVersion string  `json:"resourceVersion"`
+	StorageClass    string  `json:"storageClass,omitempty"`
+	Category        string  `json:"category"`
+	CurrentSize     string  `json:"currentSize"`
+	TargetSize      string  `json:"targetSize,omitempty"`
+	CurrentMB       int64   `json:"currentMB"`
+	TargetMB        int64   `json:"targetMB,omitempty"`
+	UsedMB          int64   `json:"usedMB"`
+	Confidence      string  `json:"confidence,omitempty"`
+	MonthlySavings  float64 `json:"monthlySavings,omitempty"`
+	Action          string  `json:"action"`
+	Reason          string  `json:"reason"`
+}
+
+// Plan is a reviewable set of PVC changes, written by `plan` and executed by `apply`
+type Plan struct {
+	Version     int        `json:"version"`
+	ClusterName string     `json:"clusterName"`
+	GeneratedAt string     `json:"generatedAt"`
+	Currency    string     `json:"currency,omitempty"`
+	Items       []PlanItem `json:"items"`
+}
+
+// quantityFromMB renders a size in MB as a Kubernetes quantity (e.g. 20Gi)
+func quantityFromMB(mb int64) string {
+	q := resource.NewQuantity(mb*1024*1024, resource.BinarySI)
+	return q.String()
+}
+
+func roundUpMB(mb, increment int64) int64 {
+	if increment <= 0 || mb%increment == 0 {
+		return mb
+	}
+	return (mb/increment + 1) * increment
+}
+
+// PlanForPVC decides the action for a single audited PVC:
+//   - expand PVCs filling fast or critically full, to fit the growth over the horizon
+//   - migrate (shrink) over-provisioned or idle PVCs with a confident recommendation
+//   - delete abandoned PVCs and StatefulSet scale-down leftovers, unless their
+//     risk (assessed with assess) blocks it — see DeleteBlocker
+//   - skip everything else, with the reason
+func PlanForPVC(pvc PVCInfo, assess RiskAssessor) PlanItem {
+	item := PlanItem{
+		Namespace:       pvc.Namespace,
+		PVC:             pvc.Name,
+		UID:             pvc.UID,
+		ResourceVersion: pvc.ResourceVersion,
+		StorageClass:    pvc.StorageClass,
+		Category:        pvc.Category,
+		CurrentSize:     quantityFromMB(pvc.AllocatedMB),
+		CurrentMB:       pvc.AllocatedMB,
+		UsedMB:          pvc.UsedMB,
+		Confidence:      pvc.Confidence,
+		Action:          ActionSkip,
+	}
+	setTarget := func(mb int64) {
+		item.TargetMB = mb
+		item.TargetSize = quantityFromMB(mb)
+	}
+
+	if pvc.Suppressed {
+		item.Reason = "suppressed: " + pvc.SuppressionReason
+		return item
+	}
+
+	switch pvc.Category {
+	case CategoryFillingFast, CategoryCritical:
+		needed := float64(pvc.UsedMB) + pvc.GrowthMBPerDay*float64(planHorizonDays)
+		target := roundUpMB(int64(needed*(1+headroomPct/100)), gib)
+		if pvc.RecommendedMB > target {
+			target = pvc.RecommendedMB
+		}
+		if target <= pvc.AllocatedMB {
+			item.Reason = "current size covers the projected usage"
+			return item
+		}
+		item.Action = ActionExpand
+		setTarget(target)
+		if pvc.Category == CategoryFillingFast {
+			item.Reason = fmt.Sprintf("full in %s days at +%.0f MB/day", FormatDaysUntilFull(pvc), pvc.GrowthMBPerDay)
+		} else {
+			item.Reason = fmt.Sprintf("%d%% used", pvc.UsedPct)
+		}
+	case CategoryOverProvisioned, CategoryIdle:
+		switch {
+		case pvc.Confidence == ConfidenceLow:
+			item.Reason = "low confidence recommendation (usage not measured)"
+		case pvc.ReclaimableMB < planMinReclaimMB:
+			item.Reason = fmt.Sprintf("reclaimable %d MB below --min-reclaim", pvc.ReclaimableMB)
+		default:
+			item.Action = ActionMigrate
+			setTarget(pvc.RecommendedMB)
+			item.MonthlySavings = pvc.SavingsCost
+			item.Reason = fmt.Sprintf("%d%% wasted, %s confidence", pvc.WastagePct, pvc.Confidence)
+		}
+	case CategoryAbandoned, CategoryScaleDownLeft:
+		reason := fmt.Sprintf("unattached and unreferenced, idle for %dd", pvc.IdleDays)
+		if pvc.Category == CategoryScaleDownLeft {
+			reason = pvc.ScaleDownLeftover
+		}
+		risk, err := assess(pvc.Namespace, pvc.Name)
+		if err != nil {
+			item.Reason = fmt.Sprintf("%s, not deleted: risk not assessed: %v", reason, err)
+			return item
+		}
+		if blocker := DeleteBlocker(risk); blocker != "" {
+			item.Reason = fmt.Sprintf("%s, not deleted: %s", reason, blocker)
+			return item
+		}
+		item.Action = ActionDelete
+		item.MonthlySavings = pvc.MonthlyCost
+		item.Reason = reason
+	case CategoryDormant:
+		item.Reason = "referenced by a workload"
+	case CategoryOrphaned:
+		item.Reason = "orphaned for less than --abandon-after"
+	case CategoryNewlyProvisioned:
+		item.Reason = "within the grace period"
+	default:
+		item.Reason = "healthy"
+	}
+	return item
+}
+
+// BuildPlan plans every PVC of an audit report
+func BuildPlan(clusterReport ClusterReport, assess RiskAssessor) Plan {
+	plan := Plan{
+		Version:     planVersion,
+		ClusterName: clusterReport.ClusterName,
+		GeneratedAt: clusterReport.GeneratedAt,
+		Currency:    clusterReport.Currency,
+	}
+	for _, nsReport := range clusterReport.NamespaceReports {
+		for _, pvc := range nsReport.PVCs {
+			plan.Items = append(plan.Items, PlanForPVC(pvc, assess))
+		}
+	}
+	return plan
+}
+
+// SavePlan writes a plan as JSON
+func SavePlan(file string, plan Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// LoadPlan reads a plan file (JSON, or YAML after review edits)
+func LoadPlan(file string) (Plan, error) {
+	var plan Plan
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return plan, fmt.Errorf("reading plan: %v", err)
+	}
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("parsing plan %s: %v", file, err)
+	}
+	if plan.Version != planVersion {
+		return plan, fmt.Errorf("plan %s has version %d, expected %d", file, plan.Version, planVersion)
+	}
+	for i, item := range plan.Items {
+		switch item.Action {
+		case ActionExpand, ActionMigrate:
+			if _, err := resource.ParseQuantity(item.TargetSize); err != nil {
+				return plan, fmt.Errorf("plan item #%d (%s/%s): invalid targetSize %q", i+1, item.Namespace, item.PVC, item.TargetSize)
+			}
+		case ActionDelete, ActionSkip:
+		default:
+			return plan, fmt.Errorf("plan item #%d (%s/%s): unknown action %q", i+1, item.Namespace, item.PVC, item.Action)
+		}
+	}
+	return plan, nil
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Audit PVCs and write a reviewable plan (expand, migrate, delete or skip per PVC)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var namespaces []string
+		if allNamespaces {
+			nsList, err := internal.ListNamespaces()
+			if err != nil {
+				return err
+			}
+			namespaces = nsList
+		} else {
+			namespaces = []string{namespace}
+		}
+
+		clusterReport, _, err := RunAudit(namespaces)
+		if err != nil {
+			return err
+		}
+		plan := BuildPlan(clusterReport, NewRiskAssessor(protectedPatterns))
+
+		if planOutput == "" {
+			os.MkdirAll("reports", 0755)
+			planOutput = filepath.Join("reports", fmt.Sprintf("pvc-plan-%s.json", time.Now().Format("20060102-150405")))
+		}
+		if err := SavePlan(planOutput, plan); err != nil {
+			return err
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Namespace", "PVC", "Action", "Current", "Target", "Used", "Reason"})
+		counts := map[string]int{}
+		var savings float64
+		for _, item := range plan.Items {
+			counts[item.Action]++
+			if item.Action == ActionSkip {
+				continue
+			}
+			savings += item.MonthlySavings
+			usedVal, usedUnit := util.FormatSizeMBorGB(item.UsedMB)
+			t.AppendRow(table.Row{item.Namespace, item.PVC, item.Action, item.CurrentSize, displayOrDash(item.TargetSize),
+				fmt.Sprintf("%.2f %s", usedVal, usedUnit), item.Reason})
+		}
+		if t.Length() > 0 {
+			t.Render()
+		}
+
+		fmt.Printf("\nPlan: %d to expand, %d to migrate, %d to delete, %d skipped\n",
+			counts[ActionExpand], counts[ActionMigrate], counts[ActionDelete], counts[ActionSkip])
+		if plan.Currency != "" {
+			fmt.Printf("Estimated monthly savings: %s\n", FormatCost(savings, plan.Currency))
+		}
+		fmt.Printf("📄 Plan written to %s — review it, then run: spacio apply %s\n", planOutput, planOutput)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	planCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Plan all namespaces")
+	planCmd.Flags().StringVarP(&planOutput, "out", "o", "", "Plan file to write (default reports/pvc-plan-<timestamp>.json)")
+	planCmd.Flags().Int64Var(&planMinReclaimMB, "min-reclaim", 1024, "Only plan migrations reclaiming at least this many MB")
+	planCmd.Flags().IntVar(&planHorizonDays, "horizon", 30, "Expansions fit the projected growth over this many days")
+	planCmd.Flags().StringSliceVar(&protectedPatterns, "protected", []string{"prod*", "*-prod", "production"}, "Namespace globs whose PVCs are never planned for deletion")
+	addAuditFlags(planCmd)
+}
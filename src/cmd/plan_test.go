@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		items   int
+		wantErr string
+	}{
+		{"JSON", "plan.json", `{"version":1,"items":[{"namespace":"db","pvc":"data","action":"migrate","targetSize":"20Gi"},{"namespace":"db","pvc":"old","action":"delete"}]}`, 2, ""},
+		{"YAML after review", "plan.yaml", "version: 1\nitems:\n- namespace: db\n  pvc: data\n  action: expand\n  targetSize: 50Gi\n- namespace: db\n  pvc: logs\n  action: skip\n", 2, ""},
+		{"missing file", "", "", 0, "reading plan"},
+		{"not a plan", "plan.json", "{", 0, "parsing plan"},
+		{"wrong version", "plan.json", `{"version":2,"items":[]}`, 0, "version 2"},
+		{"invalid target size", "plan.json", `{"version":1,"items":[{"namespace":"db","pvc":"data","action":"expand","targetSize":"big"}]}`, 0, `invalid targetSize "big"`},
+		{"missing target size", "plan.json", `{"version":1,"items":[{"namespace":"db","pvc":"data","action":"migrate"}]}`, 0, "invalid targetSize"},
+		{"unknown action", "plan.json", `{"version":1,"items":[{"namespace":"db","pvc":"data","action":"shred"}]}`, 0, `unknown action "shred"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "missing.json")
+			if tt.file != "" {
+				file = filepath.Join(t.TempDir(), tt.file)
+				if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			plan, err := LoadPlan(file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadPlan() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadPlan() error = %v", err)
+			}
+			if len(plan.Items) != tt.items {
+				t.Errorf("LoadPlan() items = %d, want %d", len(plan.Items), tt.items)
+			}
+		})
+	}
+}
+
+func TestSavePlanRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "plan.json")
+	want := Plan{Version: planVersion, ClusterName: "prod", Items: []PlanItem{{Namespace: "db", PVC: "data", Action: ActionMigrate, TargetSize: "20Gi", TargetMB: 20 * 1024}}}
+	if err := SavePlan(file, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPlan(file)
+	if err != nil {
+		t.Fatalf("LoadPlan() error = %v", err)
+	}
+	if got.ClusterName != want.ClusterName || len(got.Items) != 1 || got.Items[0] != want.Items[0] {
+		t.Errorf("LoadPlan() = %+v, want %+v", got, want)
+	}
+}
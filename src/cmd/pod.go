@@ -27,7 +27,7 @@ var podsCmd = &cobra.Command{
 
 		t := table.NewWriter()
 		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"Namespace", "PVC", "Pod(s)", "Attachment"})
+		t.AppendHeader(table.Row{"Namespace", "PVC", "Pod(s)", "Pod Phase", "Attachment"})
 
 		for _, ns := range namespaces {
 			pvcs, err := internal.ListPVCs(ns)
@@ -36,23 +36,30 @@ var podsCmd = &cobra.Command{
 				continue
 			}
 
+			pods, err := internal.ListPods(ns)
+			if err != nil {
+				fmt.Printf("Error listing pods in namespace %s: %v\n", ns, err)
+				continue
+			}
+
 			for _, pvc := range pvcs {
-				pods, err := internal.FindPodsForPVC(ns, pvc.Name)
-				if err != nil {
-					fmt.Printf("Error finding pods for PVC %s/%s: %v\n", ns, pvc.Name, err)
+				attachments := internal.PodAttachmentsForPVC(pods, pvc.Name)
+
+				if len(attachments) == 0 {
+					// unattached PVC
+					t.AppendRow(table.Row{ns, pvc.Name, "-", "-", "Unattached"})
 					continue
 				}
 
-				if len(pods) == 0 {
-					// unattached PVC
-					t.AppendRow(table.Row{ns, pvc.Name, "-", "Unattached"})
-				} else {
-					// attached PVC
-					t.AppendRow(table.Row{ns, pvc.Name, pods[0], "Attached"})
-					for _, pod := range pods[1:] {
-						// additional pods in separate rows
-						t.AppendRow(table.Row{ns, pvc.Name, pod, "Attached"})
-					}
+				// only running pods actually attach the PVC; pending or
+				// terminated pods leave it effectively unattached
+				attachment := "Attached"
+				if internal.AttachmentState(attachments) != internal.AttachmentRunning {
+					attachment = "Unattached"
+				}
+				for _, a := range attachments {
+					// one row per pod
+					t.AppendRow(table.Row{ns, pvc.Name, a.PodName, a.Phase, attachment})
 				}
 			}
 		}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+var policyFile string
+
+// Annotations that tune automatic expansion per PVC, overriding the policy file
+const (
+	AnnotationAutoExpand          = "spacio.io/autoexpand"           // "true" or "false"
+	AnnotationAutoExpandThreshold = "spacio.io/autoexpand-threshold" // used % that triggers an expansion
+	AnnotationAutoExpandIncrement = "spacio.io/autoexpand-increment" // growth per expansion, e.g. 20% or 10Gi
+	AnnotationAutoExpandMax       = "spacio.io/autoexpand-max"       // size never exceeded, e.g. 500Gi
+	AnnotationLastAutoExpand      = "spacio.io/last-autoexpand"      // RFC3339 time of the last expansion, set by autoscale
+)
+
+// Built-in auto-expand defaults, used when neither the policy nor an annotation sets a value
+const (
+	defaultExpandThreshold   = 80
+	defaultExpandIncrement   = "20%"
+	defaultExpandCooldown    = 6 * time.Hour
+	defaultExpandInterval    = time.Minute
+	defaultExpandConcurrency = 2
+)
+
+// ExpandRule sets auto-expand parameters for the PVCs it matches; empty
+// fields inherit from the policy defaults
+type ExpandRule struct {
+	Namespace    string `json:"namespace,omitempty"`    // Namespace glob (empty matches all)
+	PVC          string `json:"pvc,omitempty"`          // PVC name glob (empty matches all)
+	StorageClass string `json:"storageClass,omitempty"` // StorageClass glob (empty matches all)
+	Enabled      *bool  `json:"enabled,omitempty"`
+	Threshold    int    `json:"threshold,omitempty"` // used %
+	Increment    string `json:"increment,omitempty"` // 20% or 10Gi
+	MaxSize      string `json:"maxSize,omitempty"`
+	Cooldown     string `json:"cooldown,omitempty"` // minimum time between expansions of a volume, e.g. 6h
+}
+
+// AutoExpandPolicy configures the autoscale controller
+type AutoExpandPolicy struct {
+	Interval    string       `json:"interval,omitempty"`    // how often usage is checked (default 1m)
+	Concurrency int          `json:"concurrency,omitempty"` // expansions running at once, cluster-wide (default 2)
+	Defaults    ExpandRule   `json:"defaults"`
+	Rules       []ExpandRule `json:"rules,omitempty"` // the first matching rule applies
+}
+
+// MaintenanceWindow allows storage changes in matching namespaces for
+// Duration after each time Schedule fires, within an optional change budget
+type MaintenanceWindow struct {
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace"`            // Namespace glob (empty matches all)
+	Schedule   string `json:"schedule"`             // cron expression of the window start, e.g. "0 22 * * 1-5"
+	Duration   string `json:"duration"`             // e.g. 4h
+	Timezone   string `json:"timezone,omitempty"`   // IANA zone of the schedule (default UTC)
+	MaxChanges int    `json:"maxChanges,omitempty"` // volumes changed per window occurrence (0 = unlimited)
+	MaxBytes   string `json:"maxBytes,omitempty"`   // storage added, removed or moved per occurrence, e.g. 2Ti
+}
+
+// GovernancePolicy restricts mutating commands to maintenance windows.
+// Namespaces matched by no window are not restricted.
+type GovernancePolicy struct {
+	LedgerNamespace string              `json:"ledgerNamespace,omitempty"` // namespace of the change ledger ConfigMap (default "default")
+	Windows         []MaintenanceWindow `json:"windows,omitempty"`
+}
+
+// Policy is the on-disk format of --policy (YAML or JSON): how spacio may
+// change storage without a human in the loop
+type Policy struct {
+	AutoExpand AutoExpandPolicy `json:"autoExpand"`
+	Governance GovernancePolicy `json:"governance"`
+	Hooks      HookPolicy       `json:"hooks"`
+}
+
+// ExpandSettings are the effective auto-expand parameters of a single PVC
+type ExpandSettings struct {
+	Enabled   bool
+	Threshold int
+	Increment string
+	MaxSize   resource.Quantity // zero means unlimited
+	Cooldown  time.Duration
+}
+
+// LoadPolicy reads and validates a policy file; no file yields the built-in defaults
+func LoadPolicy(file string) (Policy, error) {
+	var policy Policy
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return policy, fmt.Errorf("reading policy file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &policy); err != nil {
+			return policy, fmt.Errorf("parsing policy file %s: %v", file, err)
+		}
+	}
+
+	ae := policy.AutoExpand
+	if ae.Interval != "" {
+		if _, err := time.ParseDuration(ae.Interval); err != nil {
+			return policy, fmt.Errorf("policy autoExpand.interval: invalid duration %q", ae.Interval)
+		}
+	}
+	if ae.Concurrency < 0 {
+		return policy, fmt.Errorf("policy autoExpand.concurrency must not be negative")
+	}
+	if err := ae.Defaults.validate(); err != nil {
+		return policy, fmt.Errorf("policy autoExpand.defaults: %v", err)
+	}
+	for i, rule := range ae.Rules {
+		if err := rule.validate(); err != nil {
+			return policy, fmt.Errorf("policy autoExpand rule #%d (%s/%s): %v", i+1, rule.Namespace, rule.PVC, err)
+		}
+	}
+	for i, w := range policy.Governance.Windows {
+		if err := w.validate(); err != nil {
+			return policy, fmt.Errorf("policy governance window #%d (%s): %v", i+1, w.DisplayName(), err)
+		}
+	}
+	for i, rule := range policy.Hooks.Rules {
+		if err := rule.validate(); err != nil {
+			return policy, fmt.Errorf("policy hooks rule #%d (%s/%s): %v", i+1, rule.Namespace, rule.PVC, err)
+		}
+	}
+	return policy, nil
+}
+
+func (r ExpandRule) validate() error {
+	if r.Threshold < 0 || r.Threshold > 100 {
+		return fmt.Errorf("threshold %d is not a percentage", r.Threshold)
+	}
+	if r.Increment != "" {
+		if _, err := ParseResizeTarget(resource.MustParse("1Gi"), "", r.Increment); err != nil {
+			return fmt.Errorf("increment: %v", err)
+		}
+	}
+	if r.MaxSize != "" {
+		if _, err := resource.ParseQuantity(r.MaxSize); err != nil {
+			return fmt.Errorf("invalid maxSize %q", r.MaxSize)
+		}
+	}
+	if r.Cooldown != "" {
+		if _, err := time.ParseDuration(r.Cooldown); err != nil {
+			return fmt.Errorf("invalid cooldown %q", r.Cooldown)
+		}
+	}
+	return nil
+}
+
+func (r ExpandRule) matches(pvc corev1.PersistentVolumeClaim) bool {
+	return globMatch(r.Namespace, pvc.Namespace) && globMatch(r.PVC, pvc.Name) && globMatch(r.StorageClass, claimStorageClass(pvc))
+}
+
+// apply overrides the settings with the fields set in the rule; values were validated on load
+func (r ExpandRule) apply(s *ExpandSettings) {
+	if r.Enabled != nil {
+		s.Enabled = *r.Enabled
+	}
+	if r.Threshold > 0 {
+		s.Threshold = r.Threshold
+	}
+	if r.Increment != "" {
+		s.Increment = r.Increment
+	}
+	if r.MaxSize != "" {
+		s.MaxSize = resource.MustParse(r.MaxSize)
+	}
+	if r.Cooldown != "" {
+		s.Cooldown, _ = time.ParseDuration(r.Cooldown)
+	}
+}
+
+// IntervalOrDefault returns how often the controller checks usage
+func (p AutoExpandPolicy) IntervalOrDefault() time.Duration {
+	if d, err := time.ParseDuration(p.Interval); err == nil && d > 0 {
+		return d
+	}
+	return defaultExpandInterval
+}
+
+// ConcurrencyOrDefault returns how many expansions may run at once
+func (p AutoExpandPolicy) ConcurrencyOrDefault() int {
+	if p.Concurrency > 0 {
+		return p.Concurrency
+	}
+	return defaultExpandConcurrency
+}
+
+// ExpandSettingsFor resolves the auto-expand settings of a PVC: built-in
+// defaults, then the policy defaults, then the first matching rule, then the
+// PVC annotations. Invalid annotations are returned as an error and ignored.
+func (p AutoExpandPolicy) ExpandSettingsFor(pvc corev1.PersistentVolumeClaim) (ExpandSettings, error) {
+	settings := ExpandSettings{
+		Threshold: defaultExpandThreshold,
+		Increment: defaultExpandIncrement,
+		Cooldown:  defaultExpandCooldown,
+	}
+	p.Defaults.apply(&settings)
+	for _, rule := range p.Rules {
+		if rule.matches(pvc) {
+			rule.apply(&settings)
+			break
+		}
+	}
+
+	var invalid []string
+	annotations := pvc.Annotations
+	if v, ok := annotations[AnnotationAutoExpand]; ok {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			settings.Enabled = enabled
+		} else {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", AnnotationAutoExpand, v))
+		}
+	}
+	if v, ok := annotations[AnnotationAutoExpandThreshold]; ok {
+		if pct, err := strconv.Atoi(strings.TrimSuffix(v, "%")); err == nil && pct > 0 && pct <= 100 {
+			settings.Threshold = pct
+		} else {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", AnnotationAutoExpandThreshold, v))
+		}
+	}
+	if v, ok := annotations[AnnotationAutoExpandIncrement]; ok {
+		if _, err := ParseResizeTarget(resource.MustParse("1Gi"), "", v); err == nil {
+			settings.Increment = v
+		} else {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", AnnotationAutoExpandIncrement, v))
+		}
+	}
+	if v, ok := annotations[AnnotationAutoExpandMax]; ok {
+		if max, err := resource.ParseQuantity(v); err == nil {
+			settings.MaxSize = max
+		} else {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", AnnotationAutoExpandMax, v))
+		}
+	}
+	if len(invalid) > 0 {
+		return settings, fmt.Errorf("ignoring invalid annotations %s", strings.Join(invalid, ", "))
+	}
+	return settings, nil
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	pricingFile    string
+	openCostURL    string
+	openCostWindow string
+)
+
+// Cost sources of a PVC
+const (
+	CostSourceCatalog  = "catalog"  // computed from the --pricing file
+	CostSourceOpenCost = "opencost" // pulled from the --opencost-url allocation API
+)
+
+// defaultCurrency is used when no pricing file sets one (OpenCost reports USD by default)
+const defaultCurrency = "USD"
+
+// hoursPerMonth is the average number of hours in a month, as cloud billing uses
+const hoursPerMonth = 730
+
+// Price is a single entry of the pricing file. An entry matches a PVC by
+// storage class name, or by provisioner plus (a subset of) the class
+// parameters, e.g. provisioner ebs.csi.aws.com with type=gp3.
+type Price struct {
+	StorageClass  string            `json:"storageClass,omitempty"`
+	Provisioner   string            `json:"provisioner,omitempty"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	PerGiBMonth   float64           `json:"perGiBMonth"`             // Capacity price per GiB-month
+	PerIOPSMonth  float64           `json:"perIOPSMonth,omitempty"`  // Price per provisioned IOPS-month
+	IncludedIOPS  int64             `json:"includedIOPS,omitempty"`  // IOPS included in the capacity price (e.g. 3000 for gp3)
+	PerMiBpsMonth float64           `json:"perMiBpsMonth,omitempty"` // Price per provisioned MiB/s-month
+	IncludedMiBps int64             `json:"includedMiBps,omitempty"` // Throughput included in the capacity price (e.g. 125 for gp3)
+}
+
+// PriceCatalog is the on-disk format of --pricing (YAML or JSON)
+type PriceCatalog struct {
+	Currency string  `json:"currency"`
+	Prices   []Price `json:"prices"`
+}
+
+// PVCCost is the monthly cost of a single PVC
+type PVCCost struct {
+	MonthlyCost float64 // Capacity plus provisioned performance
+	WastedCost  float64 // Capacity cost of the unused space
+	SavingsCost float64 // Capacity cost of the space reclaimable by right-sizing
+}
+
+// LoadPriceCatalog reads and validates a pricing file
+func LoadPriceCatalog(file string) (*PriceCatalog, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading pricing file: %v", err)
+	}
+
+	var catalog PriceCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parsing pricing file %s: %v", file, err)
+	}
+
+	for i, p := range catalog.Prices {
+		if p.StorageClass == "" && p.Provisioner == "" {
+			return nil, fmt.Errorf("price #%d: storageClass or provisioner is required", i+1)
+		}
+		if p.PerGiBMonth < 0 || p.PerIOPSMonth < 0 || p.PerMiBpsMonth < 0 {
+			return nil, fmt.Errorf("price #%d (%s%s): prices must not be negative", i+1, p.StorageClass, p.Provisioner)
+		}
+	}
+	if catalog.Currency == "" {
+		catalog.Currency = defaultCurrency
+	}
+	return &catalog, nil
+}
+
+// PriceFor returns the price of a storage class. An entry naming the class
+// wins; otherwise the provisioner entry with the most matching parameters.
+func (c *PriceCatalog) PriceFor(className string, sc storagev1.StorageClass) (Price, bool) {
+	if c == nil {
+		return Price{}, false
+	}
+
+	best, bestScore := Price{}, -1
+	for _, p := range c.Prices {
+		if p.StorageClass != "" {
+			if p.StorageClass == className {
+				return p, true
+			}
+			continue
+		}
+		if p.Provisioner != sc.Provisioner || !parametersMatch(p.Parameters, sc.Parameters) {
+			continue
+		}
+		if len(p.Parameters) > bestScore {
+			best, bestScore = p, len(p.Parameters)
+		}
+	}
+	return best, bestScore >= 0
+}
+
+func parametersMatch(want, have map[string]string) bool {
+	for k, v := range want {
+		if !strings.EqualFold(have[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProvisionedPerformance returns the IOPS and throughput (MiB/s) provisioned
+// by the class parameters of the common CSI drivers, 0 when not set
+func ProvisionedPerformance(params map[string]string, sizeGiB float64) (iops, mibps int64) {
+	for _, key := range []string{"iops", "provisioned-iops-on-create", "DiskIOPSReadWrite"} {
+		if v, err := strconv.ParseInt(params[key], 10, 64); err == nil {
+			iops = v
+		}
+	}
+	if v, err := strconv.ParseFloat(params["iopsPerGB"], 64); err == nil && iops == 0 {
+		iops = int64(v * sizeGiB)
+	}
+	for _, key := range []string{"throughput", "provisioned-throughput-on-create", "DiskMBpsReadWrite"} {
+		if v, err := strconv.ParseInt(strings.TrimSuffix(params[key], "Mi"), 10, 64); err == nil {
+			mibps = v
+		}
+	}
+	return iops, mibps
+}
+
+// ComputeCost prices a PVC: capacity plus provisioned performance beyond what
+// the capacity price includes. Wasted and savings costs only count capacity,
+// since performance is provisioned independently of the used space.
+func ComputeCost(pvc PVCInfo, price Price, params map[string]string) PVCCost {
+	allocGiB := float64(pvc.AllocatedMB) / 1024
+	cost := PVCCost{
+		MonthlyCost: allocGiB * price.PerGiBMonth,
+		WastedCost:  float64(pvc.WastedMB) / 1024 * price.PerGiBMonth,
+		SavingsCost: float64(pvc.ReclaimableMB) / 1024 * price.PerGiBMonth,
+	}
+
+	iops, mibps := ProvisionedPerformance(params, allocGiB)
+	if extra := iops - price.IncludedIOPS; extra > 0 {
+		cost.MonthlyCost += float64(extra) * price.PerIOPSMonth
+	}
+	if extra := mibps - price.IncludedMiBps; extra > 0 {
+		cost.MonthlyCost += float64(extra) * price.PerMiBpsMonth
+	}
+	return cost
+}
+
+// ParseCostWindow parses an OpenCost window such as "7d", "24h" or "90m"
+func ParseCostWindow(window string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid OpenCost window %q", window)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid OpenCost window %q, want e.g. 7d or 24h", window)
+	}
+	return d, nil
+}
+
+// OpenCostToMonthly converts the cost of a PV over an OpenCost window to a
+// monthly cost. The hours the PV existed are derived from its byte-hours, so
+// PVs created during the window are not underestimated.
+func OpenCostToMonthly(pvc PVCInfo, pv internal.OpenCostPV, window time.Duration) PVCCost {
+	hours := window.Hours()
+	if bytes := float64(pvc.AllocatedMB) * 1024 * 1024; bytes > 0 && pv.ByteHours > 0 {
+		if h := pv.ByteHours / bytes; h < hours {
+			hours = h
+		}
+	}
+	if hours <= 0 {
+		return PVCCost{}
+	}
+
+	monthly := pv.Cost / hours * hoursPerMonth
+	cost := PVCCost{MonthlyCost: monthly}
+	if pvc.AllocatedMB > 0 {
+		cost.WastedCost = monthly * float64(pvc.WastedMB) / float64(pvc.AllocatedMB)
+		cost.SavingsCost = monthly * float64(pvc.ReclaimableMB) / float64(pvc.AllocatedMB)
+	}
+	return cost
+}
+
+// NamespaceCost aggregates the cost of the PVCs of a namespace
+type NamespaceCost struct {
+	Namespace   string
+	MonthlyCost float64
+	WastedCost  float64
+	SavingsCost float64
+}
+
+// NamespaceCosts aggregates priced PVCs per namespace, highest wasted cost first
+func NamespaceCosts(clusterReport ClusterReport) []NamespaceCost {
+	var costs []NamespaceCost
+	for _, nsReport := range clusterReport.NamespaceReports {
+		c := NamespaceCost{Namespace: nsReport.Namespace}
+		for _, pvc := range nsReport.PVCs {
+			c.MonthlyCost += pvc.MonthlyCost
+			c.WastedCost += pvc.WastedCost
+			if countsTowardSavings(pvc) {
+				c.SavingsCost += pvc.SavingsCost
+			}
+		}
+		costs = append(costs, c)
+	}
+	sort.SliceStable(costs, func(i, j int) bool { return costs[i].WastedCost > costs[j].WastedCost })
+	return costs
+}
+
+// FormatCost formats an amount with its currency, e.g. "123.45 USD"
+func FormatCost(amount float64, currency string) string {
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+// CostSummary renders the storage cost section of the CLI report
+func CostSummary(clusterReport ClusterReport) string {
+	if clusterReport.Currency == "" {
+		return ""
+	}
+	report := strings.Builder{}
+	currency := clusterReport.Currency
+
+	report.WriteString("\n💰 Storage Cost (monthly)\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	report.WriteString(fmt.Sprintf("Total Cost               : %s\n", FormatCost(clusterReport.TotalMonthlyCost, currency)))
+	report.WriteString(fmt.Sprintf("Wasted Cost              : %s\n", FormatCost(clusterReport.TotalWastedCost, currency)))
+	report.WriteString(fmt.Sprintf("Potential Savings        : %s\n", FormatCost(clusterReport.TotalSavingsCost, currency)))
+	if clusterReport.UnpricedPVCs > 0 {
+		report.WriteString(fmt.Sprintf("Unpriced PVCs            : %d (no matching price)\n", clusterReport.UnpricedPVCs))
+	}
+	for _, c := range NamespaceCosts(clusterReport) {
+		report.WriteString(fmt.Sprintf("  %-30s cost %s, wasted %s, savings %s\n", c.Namespace,
+			FormatCost(c.MonthlyCost, currency), FormatCost(c.WastedCost, currency), FormatCost(c.SavingsCost, currency)))
+	}
+
+	// the most expensive wasted PVCs, where budget attention pays off first
+	var pvcs []PVCInfo
+	for _, nsReport := range clusterReport.NamespaceReports {
+		for _, pvc := range nsReport.PVCs {
+			if pvc.WastedCost > 0 && !pvc.Suppressed {
+				pvcs = append(pvcs, pvc)
+			}
+		}
+	}
+	sort.SliceStable(pvcs, func(i, j int) bool { return pvcs[i].WastedCost > pvcs[j].WastedCost })
+	if len(pvcs) > 5 {
+		pvcs = pvcs[:5]
+	}
+	if len(pvcs) > 0 {
+		report.WriteString("Top Wasted Cost PVCs:\n")
+	}
+	for _, pvc := range pvcs {
+		wastedVal, wastedUnit := util.FormatSizeMBorGB(pvc.WastedMB)
+		report.WriteString(fmt.Sprintf("  %s/%s — wasted %s (%.2f %s of %s/month)\n", pvc.Namespace, pvc.Name,
+			FormatCost(pvc.WastedCost, currency), wastedVal, wastedUnit, FormatCost(pvc.MonthlyCost, currency)))
+	}
+
+	return report.String()
+}
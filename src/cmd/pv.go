@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var pvFlaggedOnly bool
+
+// PV findings
+const (
+	PVFindingReleased     = "Released (claim deleted)"
+	PVFindingAvailable    = "Available (never claimed)"
+	PVFindingFailed       = "Failed (reclamation failed)"
+	PVFindingMissingClaim = "Claim missing"
+)
+
+// CollectPVInfos lists every PV with its finding. A PV is flagged when it is
+// Released, Available or Failed, or when its claimRef points to a PVC that no
+// longer exists.
+func CollectPVInfos() ([]PVInfo, error) {
+	pvs, err := internal.ListPVs()
+	if err != nil {
+		return nil, err
+	}
+
+	// all PVCs in the cluster, keyed by namespace/name and UID
+	pvcs, err := internal.ListPVCs("")
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]string{}
+	for _, pvc := range pvcs {
+		claims[pvcKey(pvc.Namespace, pvc.Name)] = string(pvc.UID)
+	}
+
+	now := time.Now()
+	infos := make([]PVInfo, 0, len(pvs))
+	for _, pv := range pvs {
+		driver, handle := internal.PVSource(pv)
+		info := PVInfo{
+			Name:          pv.Name,
+			Phase:         string(pv.Status.Phase),
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+			StorageClass:  pv.Spec.StorageClassName,
+			CapacityMB:    pv.Spec.Capacity.Storage().Value() / 1024 / 1024,
+			CSIDriver:     driver,
+			VolumeHandle:  handle,
+			AgeDays:       int(now.Sub(pv.CreationTimestamp.Time).Hours() / 24),
+		}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			info.ClaimRef = pvcKey(ref.Namespace, ref.Name)
+		}
+
+		switch pv.Status.Phase {
+		case corev1.VolumeReleased:
+			info.Finding = PVFindingReleased
+		case corev1.VolumeAvailable:
+			info.Finding = PVFindingAvailable
+		case corev1.VolumeFailed:
+			info.Finding = PVFindingFailed
+		case corev1.VolumeBound:
+			uid, exists := claims[info.ClaimRef]
+			if !exists || (pv.Spec.ClaimRef.UID != "" && string(pv.Spec.ClaimRef.UID) != uid) {
+				info.Finding = PVFindingMissingClaim
+			}
+		}
+
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// FlaggedPVs filters PV infos down to the flagged ones
+func FlaggedPVs(infos []PVInfo) ([]PVInfo, int64) {
+	var flagged []PVInfo
+	var totalMB int64
+	for _, info := range infos {
+		if info.Finding != "" {
+			flagged = append(flagged, info)
+			totalMB += info.CapacityMB
+		}
+	}
+	return flagged, totalMB
+}
+
+// PVFindingsSummary renders the PersistentVolume section of the CLI report
+func PVFindingsSummary(clusterReport ClusterReport) string {
+	if len(clusterReport.PVFindings) == 0 {
+		return ""
+	}
+	report := strings.Builder{}
+
+	report.WriteString("\n💽 PersistentVolume Findings\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	for _, pv := range clusterReport.PVFindings {
+		capVal, capUnit := util.FormatSizeMBorGB(pv.CapacityMB)
+		claim := pv.ClaimRef
+		if claim == "" {
+			claim = "-"
+		}
+		report.WriteString(fmt.Sprintf("  %s — %s, %.2f %s, reclaim %s, class %s, claim %s, %s %s\n",
+			pv.Name, pv.Finding, capVal, capUnit, pv.ReclaimPolicy, pv.StorageClass, claim, pv.CSIDriver, pv.VolumeHandle))
+	}
+	totalVal, totalUnit := util.FormatSizeMBorGB(clusterReport.PVFindingsMB)
+	report.WriteString(fmt.Sprintf("Flagged PV Capacity      : %.2f %s\n", totalVal, totalUnit))
+
+	return report.String()
+}
+
+var pvCmd = &cobra.Command{
+	Use:   "pv",
+	Short: "Audit PersistentVolumes (Released, Available, Failed and claim-less PVs)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := CollectPVInfos()
+		if err != nil {
+			return err
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Name", "Phase", "Reclaim Policy", "Storage Class", "Capacity", "CSI Driver", "Volume Handle", "Claim", "Age", "Finding"})
+
+		for _, pv := range infos {
+			if pvFlaggedOnly && pv.Finding == "" {
+				continue
+			}
+			capVal, capUnit := util.FormatSizeMBorGB(pv.CapacityMB)
+			finding := pv.Finding
+			if finding == "" {
+				finding = "-"
+			} else {
+				finding = "⚠️  " + finding
+			}
+			t.AppendRow(table.Row{
+				pv.Name,
+				pv.Phase,
+				pv.ReclaimPolicy,
+				pv.StorageClass,
+				fmt.Sprintf("%.2f %s", capVal, capUnit),
+				pv.CSIDriver,
+				pv.VolumeHandle,
+				pv.ClaimRef,
+				fmt.Sprintf("%dd", pv.AgeDays),
+				finding,
+			})
+		}
+
+		if t.Length() == 0 {
+			fmt.Println("No PersistentVolumes found")
+			return nil
+		}
+
+		t.Render()
+
+		flagged, flaggedMB := FlaggedPVs(infos)
+		totalVal, totalUnit := util.FormatSizeMBorGB(flaggedMB)
+		fmt.Printf("\nFlagged PVs: %d of %d (%.2f %s)\n", len(flagged), len(infos), totalVal, totalUnit)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pvCmd)
+	pvCmd.Flags().BoolVar(&pvFlaggedOnly, "flagged", false, "Only show flagged PVs")
+}
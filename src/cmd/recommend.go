@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+)
+
+// Recommendation confidence levels
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+var (
+	headroomPct float64 // extra space on top of used/peak bytes
+	growthPct   float64 // allowance for future growth
+)
+
+// minStableAge is how long a PVC must have existed for its usage to be considered stable
+const minStableAge = 7 * 24 * time.Hour
+
+// SizingRule is the minimum size and allocation increment of a provisioner, in MB
+type SizingRule struct {
+	MinMB       int64
+	IncrementMB int64
+}
+
+const gib = 1024
+
+// SizingRuleFor returns the sizing constraints of a provisioner. Parameters
+// (e.g. EBS type, GCE replication type, Azure Files SKU) refine the minimum.
+func SizingRuleFor(provisioner string, params map[string]string) SizingRule {
+	rule := SizingRule{MinMB: 1 * gib, IncrementMB: 1 * gib}
+
+	switch provisioner {
+	case "ebs.csi.aws.com", "kubernetes.io/aws-ebs":
+		switch strings.ToLower(params["type"]) {
+		case "io1", "io2":
+			rule.MinMB = 4 * gib
+		case "st1", "sc1":
+			rule.MinMB = 125 * gib
+		}
+	case "pd.csi.storage.gke.io", "kubernetes.io/gce-pd":
+		rule.MinMB = 10 * gib
+		if strings.Contains(strings.ToLower(params["replication-type"]), "regional") {
+			rule.MinMB = 200 * gib
+		}
+		if strings.HasPrefix(strings.ToLower(params["type"]), "hyperdisk") {
+			rule.MinMB = 4 * gib
+		}
+	case "disk.csi.azure.com", "kubernetes.io/azure-disk":
+		rule.MinMB = 4 * gib
+	case "file.csi.azure.com", "kubernetes.io/azure-file":
+		if strings.Contains(strings.ToLower(params["skuName"]), "premium") {
+			rule.MinMB = 100 * gib
+		}
+	}
+	return rule
+}
+
+// Recommendation is the right-sizing result for a single PVC
+type Recommendation struct {
+	RecommendedMB int64
+	ReclaimableMB int64
+	Confidence    string
+}
+
+// RecommendSize computes a recommended size from used (or peak) space plus
+// headroom and growth allowance, rounded up to the provisioner's increment and
+// minimum. The recommendation may exceed the current allocation for PVCs that
+// are running out of space.
+func RecommendSize(pvc PVCInfo, rule SizingRule, now time.Time) Recommendation {
+	basis := pvc.UsedMB
+	if pvc.PeakUsedMB > basis {
+		basis = pvc.PeakUsedMB
+	}
+
+	target := float64(basis) * (1 + headroomPct/100) * (1 + growthPct/100)
+	recommended := int64(target)
+	if float64(recommended) < target {
+		recommended++
+	}
+	if rule.IncrementMB > 0 && recommended%rule.IncrementMB != 0 {
+		recommended = (recommended/rule.IncrementMB + 1) * rule.IncrementMB
+	}
+	if recommended < rule.MinMB {
+		recommended = rule.MinMB
+	}
+
+	rec := Recommendation{RecommendedMB: recommended, Confidence: recommendationConfidence(pvc, now)}
+	if pvc.AllocatedMB > recommended {
+		rec.ReclaimableMB = pvc.AllocatedMB - recommended
+	}
+	return rec
+}
+
+// recommendationConfidence is high for measured, stable PVCs, medium for young
+// ones, and low when usage could not be measured (no running pod).
+func recommendationConfidence(pvc PVCInfo, now time.Time) string {
+	if !pvc.Attached {
+		return ConfidenceLow
+	}
+	if pvc.PeakUsedMB == 0 && !pvc.CreatedAt.IsZero() && now.Sub(pvc.CreatedAt) < minStableAge {
+		return ConfidenceMedium
+	}
+	return ConfidenceHigh
+}
+
+// countsTowardSavings reports whether the reclaimable space of a PVC is added
+// to the savings totals. Low-confidence recommendations are left out: without
+// a running pod the usage is unknown, so the whole volume would be counted.
+func countsTowardSavings(pvc PVCInfo) bool {
+	return !pvc.Suppressed && pvc.Category != CategoryDormant && pvc.Confidence != ConfidenceLow
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecommendSize(t *testing.T) {
+	headroomPct, growthPct = 20, 0
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-30 * 24 * time.Hour)
+	rule := SizingRule{MinMB: 1 * gib, IncrementMB: 1 * gib}
+
+	tests := []struct {
+		name string
+		pvc  PVCInfo
+		rule SizingRule
+		want Recommendation
+	}{
+		{"rounded up to the increment", PVCInfo{Attached: true, AllocatedMB: 10 * gib, UsedMB: 2 * gib, CreatedAt: old}, rule,
+			Recommendation{RecommendedMB: 3 * gib, ReclaimableMB: 7 * gib, Confidence: ConfidenceHigh}},
+		{"peak above used", PVCInfo{Attached: true, AllocatedMB: 10 * gib, UsedMB: 1 * gib, PeakUsedMB: 5 * gib, CreatedAt: old}, rule,
+			Recommendation{RecommendedMB: 6 * gib, ReclaimableMB: 4 * gib, Confidence: ConfidenceHigh}},
+		{"provisioner minimum", PVCInfo{Attached: true, AllocatedMB: 200 * gib, UsedMB: 1 * gib, CreatedAt: old}, SizingRule{MinMB: 125 * gib, IncrementMB: 1 * gib},
+			Recommendation{RecommendedMB: 125 * gib, ReclaimableMB: 75 * gib, Confidence: ConfidenceHigh}},
+		{"running out of space", PVCInfo{Attached: true, AllocatedMB: 10 * gib, UsedMB: 9 * gib, CreatedAt: old}, rule,
+			Recommendation{RecommendedMB: 11 * gib, Confidence: ConfidenceHigh}},
+		{"young PVC", PVCInfo{Attached: true, AllocatedMB: 10 * gib, UsedMB: 2 * gib, CreatedAt: now.Add(-24 * time.Hour)}, rule,
+			Recommendation{RecommendedMB: 3 * gib, ReclaimableMB: 7 * gib, Confidence: ConfidenceMedium}},
+		{"young PVC with history", PVCInfo{Attached: true, AllocatedMB: 10 * gib, UsedMB: 2 * gib, PeakUsedMB: 2 * gib, CreatedAt: now.Add(-24 * time.Hour)}, rule,
+			Recommendation{RecommendedMB: 3 * gib, ReclaimableMB: 7 * gib, Confidence: ConfidenceHigh}},
+		{"unmeasured", PVCInfo{AllocatedMB: 10 * gib, CreatedAt: old}, rule,
+			Recommendation{RecommendedMB: 1 * gib, ReclaimableMB: 9 * gib, Confidence: ConfidenceLow}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RecommendSize(tt.pvc, tt.rule, now); got != tt.want {
+				t.Errorf("RecommendSize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
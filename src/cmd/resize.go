@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	resizePVC     string
+	resizeTo      string
+	resizeBy      string
+	resizeTimeout time.Duration
+	resizeRestart bool
+)
+
+const (
+	// resizePollInterval is how often the PVC is re-read while following an expansion
+	resizePollInterval = 2 * time.Second
+	// fsPendingGrace is how long a filesystem resize may stay pending on a
+	// running pod before the expansion is reported as needing a restart
+	fsPendingGrace = time.Minute
+)
+
+// Phases reported while expanding a PVC
+const (
+	ResizePhaseValidated = "Validated"
+	ResizePhaseRequested = "Requested"
+	ResizePhaseResizing  = "Resizing"
+	ResizePhaseFSPending = "FileSystemResizePending"
+	ResizePhaseCompleted = "Completed"
+	ResizePhaseFailed    = "Failed"
+	ResizePhaseTimedOut  = "TimedOut"
+)
+
+// ResizeResult is the outcome of expanding a single PVC
+type ResizeResult struct {
+	Namespace  string
+	PVC        string
+	From       resource.Quantity
+	To         resource.Quantity
+	Phase      string // last phase reached
+	Message    string
+	Duration   time.Duration
+	Filesystem FilesystemCheck // filesystem seen from the pod once the expansion completed
+}
+
+// Logf receives progress messages of long-running operations
+type Logf func(format string, args ...interface{})
+
+// phaseError prefixes an error with the phase it happened in, once one was reached
+func phaseError(phase string, err error) string {
+	if phase == "" {
+		return err.Error()
+	}
+	return phase + ": " + err.Error()
+}
+
+// CurrentSize is the larger of the requested and the actual capacity of a PVC
+func CurrentSize(pvc corev1.PersistentVolumeClaim) resource.Quantity {
+	size := pvc.Spec.Resources.Requests.Storage().DeepCopy()
+	if capacity := pvc.Status.Capacity.Storage(); capacity.Cmp(size) > 0 {
+		size = capacity.DeepCopy()
+	}
+	return size
+}
+
+// ParseResizeTarget computes the target size from --to (absolute, e.g. 200Gi)
+// or --by (relative, e.g. 20% or 10Gi)
+func ParseResizeTarget(current resource.Quantity, to, by string) (resource.Quantity, error) {
+	switch {
+	case to != "" && by != "":
+		return resource.Quantity{}, fmt.Errorf("use either --to or --by, not both")
+	case to != "":
+		target, err := resource.ParseQuantity(to)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid --to %q: %v", to, err)
+		}
+		return target, nil
+	case strings.HasSuffix(by, "%"):
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(by, "%"), 64)
+		if err != nil || pct <= 0 {
+			return resource.Quantity{}, fmt.Errorf("invalid --by %q, want e.g. 20%%", by)
+		}
+		// round up to a whole GiB (MiB for small volumes) so providers accept the size
+		unit := int64(1024 * 1024 * 1024)
+		if current.Value() < unit {
+			unit = 1024 * 1024
+		}
+		bytes := int64(float64(current.Value()) * (1 + pct/100))
+		bytes = (bytes + unit - 1) / unit * unit
+		return *resource.NewQuantity(bytes, resource.BinarySI), nil
+	case by != "":
+		delta, err := resource.ParseQuantity(by)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid --by %q: %v", by, err)
+		}
+		target := current.DeepCopy()
+		target.Add(delta)
+		return target, nil
+	default:
+		return resource.Quantity{}, fmt.Errorf("--to or --by is required")
+	}
+}
+
+// ValidateExpansion checks that a PVC can be expanded to the target size:
+// it is Bound, the target is larger, its StorageClass allows expansion and the
+// namespace ResourceQuotas leave room for the extra storage
+func ValidateExpansion(pvc corev1.PersistentVolumeClaim, target resource.Quantity) error {
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return fmt.Errorf("PVC %s/%s is %s, only Bound PVCs can be expanded", pvc.Namespace, pvc.Name, pvc.Status.Phase)
+	}
+	current := CurrentSize(pvc)
+	if target.Cmp(current) <= 0 {
+		return fmt.Errorf("target %s is not larger than the current size %s: PVCs cannot be shrunk in place", target.String(), current.String())
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return fmt.Errorf("PVC %s/%s has no storage class, expansion is not supported", pvc.Namespace, pvc.Name)
+	}
+	sc, err := internal.GetStorageClass(*pvc.Spec.StorageClassName)
+	if err != nil {
+		return fmt.Errorf("getting storage class %s: %v", *pvc.Spec.StorageClassName, err)
+	}
+	if !internal.AllowsExpansion(*sc) {
+		return fmt.Errorf("storage class %s does not allow volume expansion (allowVolumeExpansion is not true)", sc.Name)
+	}
+
+	delta := target.DeepCopy()
+	delta.Sub(*pvc.Spec.Resources.Requests.Storage())
+	return CheckStorageQuota(pvc, delta)
+}
+
+// CheckStorageQuota checks that requesting delta more storage stays within the
+// namespace ResourceQuotas, total and per storage class
+func CheckStorageQuota(pvc corev1.PersistentVolumeClaim, delta resource.Quantity) error {
+	quotas, err := internal.ListResourceQuotas(pvc.Namespace)
+	if err != nil {
+		return fmt.Errorf("listing ResourceQuotas in %s: %v", pvc.Namespace, err)
+	}
+
+	keys := []corev1.ResourceName{corev1.ResourceRequestsStorage}
+	if pvc.Spec.StorageClassName != nil {
+		keys = append(keys, corev1.ResourceName(*pvc.Spec.StorageClassName+".storageclass.storage.k8s.io/requests.storage"))
+	}
+	for _, quota := range quotas {
+		for _, key := range keys {
+			hard, ok := quota.Status.Hard[key]
+			if !ok {
+				continue
+			}
+			used := quota.Status.Used[key]
+			after := used.DeepCopy()
+			after.Add(delta)
+			if after.Cmp(hard) > 0 {
+				return fmt.Errorf("ResourceQuota %s: %s would exceed the hard limit %s (%s used + %s)",
+					quota.Name, key, hard.String(), used.String(), delta.String())
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandPVC validates and expands a PVC, then follows its conditions until
+// the capacity is updated or the timeout hits, and verifies the filesystem
+// grew from inside the pod mounting it. A timeout of 0 returns right after
+// the request is patched. Filesystem expansion pending on an unmounted volume
+// is reported as such, since it only completes when a pod mounts it.
+func ExpandPVC(ns, pvcName string, target resource.Quantity, timeout time.Duration, logf Logf) (ResizeResult, error) {
+	start := time.Now()
+	result := ResizeResult{Namespace: ns, PVC: pvcName, To: target}
+
+	pvc, err := internal.GetPVC(ns, pvcName)
+	if err != nil {
+		return result, fmt.Errorf("getting PVC %s/%s: %v", ns, pvcName, err)
+	}
+	result.From = CurrentSize(*pvc)
+	if err := ValidateExpansion(*pvc, target); err != nil {
+		result.Phase = ResizePhaseFailed
+		return result, err
+	}
+	result.Phase = ResizePhaseValidated
+	logf("%s: storage class allows expansion, quota leaves room for %s → %s", result.Phase, result.From.String(), target.String())
+
+	// the filesystem size before tells how much it must grow
+	var before resource.Quantity
+	if timeout > 0 {
+		if check, err := measureFilesystem(ns, pvcName); err == nil {
+			before = check.Size
+		}
+	}
+
+	if _, err := internal.PatchPVCStorageRequest(ns, pvcName, target); err != nil {
+		result.Phase = ResizePhaseFailed
+		return result, fmt.Errorf("patching PVC %s/%s: %v", ns, pvcName, err)
+	}
+	result.Phase = ResizePhaseRequested
+	logf("%s: spec.resources.requests.storage set to %s", result.Phase, target.String())
+	if timeout == 0 {
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	// the request is made: whatever happens next, the volume is growing
+	err = WaitForExpansion(&result, start, timeout, logf)
+	if err == nil {
+		// a resize still pending on a running pod already had fsPendingGrace
+		wait := fsVerifyTimeout
+		if result.Phase == ResizePhaseFSPending {
+			wait = 0
+		}
+		result.Filesystem = VerifyFilesystem(result, before, wait, logf)
+		logf("Filesystem %s: %s", result.Filesystem.Status, result.Filesystem.Message)
+	}
+	result.Duration = time.Since(start)
+	return result, changeMade(err)
+}
+
+// WaitForExpansion polls a PVC whose request was raised until its capacity
+// reaches the target, reporting every phase change
+func WaitForExpansion(result *ResizeResult, start time.Time, timeout time.Duration, logf Logf) error {
+	deadline := start.Add(timeout)
+	seenEvents := map[string]bool{}
+	var pendingSince time.Time
+	var lastError string // last resize error condition seen, reported on timeout
+
+	for {
+		pvc, err := internal.GetPVC(result.Namespace, result.PVC)
+		if err != nil {
+			return fmt.Errorf("getting PVC %s/%s: %v", result.Namespace, result.PVC, err)
+		}
+
+		if capacity := pvc.Status.Capacity.Storage(); capacity.Cmp(result.To) >= 0 {
+			result.Phase = ResizePhaseCompleted
+			result.Message = "capacity is " + capacity.String()
+			logf("%s: %s after %s", result.Phase, result.Message, time.Since(start).Round(time.Second))
+			return nil
+		}
+
+		// resize errors reported by the external-resizer or kubelet are
+		// retried by them, so they are surfaced like warning events; only an
+		// infeasible resize is final
+		for _, t := range []corev1.PersistentVolumeClaimConditionType{corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError} {
+			if c := internal.PVCCondition(*pvc, t); c != nil {
+				lastError = fmt.Sprintf("%s: %s", t, strings.TrimSpace(c.Message))
+				if !seenEvents[lastError] {
+					seenEvents[lastError] = true
+					logf("⚠️  %s, retried by the resizer", lastError)
+				}
+			}
+		}
+		switch pvc.Status.AllocatedResourceStatuses[corev1.ResourceStorage] {
+		case corev1.PersistentVolumeClaimControllerResizeInfeasible, corev1.PersistentVolumeClaimNodeResizeInfeasible:
+			result.Phase = ResizePhaseFailed
+			result.Message = string(pvc.Status.AllocatedResourceStatuses[corev1.ResourceStorage])
+			return fmt.Errorf("expansion is infeasible: %s", result.Message)
+		}
+
+		phase := ResizePhaseRequested
+		if internal.PVCCondition(*pvc, corev1.PersistentVolumeClaimResizing) != nil {
+			phase = ResizePhaseResizing
+		}
+		if internal.PVCCondition(*pvc, corev1.PersistentVolumeClaimFileSystemResizePending) != nil {
+			phase = ResizePhaseFSPending
+		}
+		if phase != result.Phase {
+			result.Phase = phase
+			switch phase {
+			case ResizePhaseResizing:
+				logf("%s: the volume is being expanded by the storage provider", phase)
+			case ResizePhaseFSPending:
+				logf("%s: volume expanded, waiting for the kubelet to grow the filesystem", phase)
+			}
+		}
+
+		// the filesystem only grows while a pod mounts the volume, and only
+		// on remount when the CSI driver cannot expand online
+		if phase == ResizePhaseFSPending {
+			attachments, err := internal.FindPodAttachmentsForPVC(result.Namespace, result.PVC)
+			if err == nil && internal.AttachmentState(attachments) != internal.AttachmentRunning {
+				result.Message = "filesystem resize completes when a pod mounts the volume"
+				logf("%s: no running pod mounts the PVC, %s", phase, result.Message)
+				return nil
+			}
+			if pendingSince.IsZero() {
+				pendingSince = time.Now()
+			} else if time.Since(pendingSince) > fsPendingGrace {
+				result.Message = "filesystem resize still pending on the running pod"
+				logf("%s: %s after %s", phase, result.Message, fsPendingGrace)
+				return nil
+			}
+		}
+
+		// surface resizer warnings once; they are often retried successfully
+		if events, err := internal.ListEventsForObject(result.Namespace, "PersistentVolumeClaim", result.PVC); err == nil {
+			for _, e := range events {
+				if e.Type != corev1.EventTypeWarning || internal.EventTime(e).Before(start.Add(-time.Second)) || seenEvents[e.Message] {
+					continue
+				}
+				seenEvents[e.Message] = true
+				logf("⚠️  %s: %s", e.Reason, strings.TrimSpace(e.Message))
+			}
+		}
+
+		if time.Now().After(deadline) {
+			result.Message = fmt.Sprintf("still %s after %s", result.Phase, timeout)
+			phase := result.Phase
+			result.Phase = ResizePhaseTimedOut
+			if lastError != "" {
+				return fmt.Errorf("timed out after %s waiting for PVC %s/%s to expand (last phase %s, %s)", timeout, result.Namespace, result.PVC, phase, lastError)
+			}
+			return fmt.Errorf("timed out after %s waiting for PVC %s/%s to expand (last phase %s)", timeout, result.Namespace, result.PVC, phase)
+		}
+		time.Sleep(resizePollInterval)
+	}
+}
+
+var resizeCmd = &cobra.Command{
+	Use:   "resize",
+	Short: "Expand a PVC after checking StorageClass expansion support and ResourceQuotas",
+	Example: `  spacio resize -n db -p data-postgres-0 --to 200Gi
+  spacio resize -n db -p data-postgres-0 --by 20%`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pvc, err := internal.GetPVC(namespace, resizePVC)
+		if err != nil {
+			return fmt.Errorf("getting PVC %s/%s: %v", namespace, resizePVC, err)
+		}
+		current := CurrentSize(*pvc)
+		target, err := ParseResizeTarget(current, resizeTo, resizeBy)
+		if err != nil {
+			return err
+		}
+
+		guard, hooks, err := newCommandGuard()
+		if err != nil {
+			return err
+		}
+		logf := func(format string, args ...interface{}) {
+			fmt.Printf("  ↳ "+format+"\n", args...)
+		}
+
+		fmt.Printf("📏 Resizing PVC %s/%s from %s to %s\n", namespace, resizePVC, current.String(), target.String())
+		delta := target.DeepCopy()
+		delta.Sub(current)
+		var result ResizeResult
+		payload := HookPayload{Action: ActionExpand, From: current.String(), To: target.String()}
+		err = guard.Run(Change{Namespace: namespace, Name: resizePVC, Action: ActionExpand, Bytes: delta.Value()}, logf, func() error {
+			return hooks.Run(*pvc, payload, logf, func() error {
+				var err error
+				result, err = ExpandPVC(namespace, resizePVC, target, resizeTimeout, logf)
+				return err
+			})
+		})
+		if err != nil {
+			fmt.Printf("❌ %s\n", phaseError(result.Phase, err))
+			return err
+		}
+
+		if err := settleFilesystem(&result, resizeRestart, resizeTimeout, logf); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
+
+		switch {
+		case result.Filesystem.Status == FilesystemVerified:
+			fmt.Printf("✅ PVC %s/%s expanded to %s in %s, %s\n", namespace, resizePVC, target.String(), result.Duration.Round(time.Second), result.Filesystem.Message)
+		case result.Filesystem.Status == FilesystemPending:
+			fmt.Printf("⏳ PVC %s/%s volume expanded to %s; %s (or use --restart-pod)\n", namespace, resizePVC, target.String(), result.Filesystem.Message)
+		case result.Phase == ResizePhaseFSPending:
+			fmt.Printf("⏳ PVC %s/%s volume expanded; %s\n", namespace, resizePVC, result.Message)
+		case result.Phase == ResizePhaseCompleted:
+			fmt.Printf("✅ PVC %s/%s expanded to %s in %s\n", namespace, resizePVC, target.String(), result.Duration.Round(time.Second))
+		default:
+			fmt.Printf("📝 Expansion of PVC %s/%s requested (not waiting)\n", namespace, resizePVC)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resizeCmd)
+	resizeCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	resizeCmd.Flags().StringVarP(&resizePVC, "pvc", "p", "", "PVC name")
+	resizeCmd.Flags().StringVar(&resizeTo, "to", "", "Target size (e.g. 200Gi)")
+	resizeCmd.Flags().StringVar(&resizeBy, "by", "", "Grow by a percentage or an amount (e.g. 20% or 10Gi)")
+	resizeCmd.Flags().DurationVar(&resizeTimeout, "timeout", 5*time.Minute, "How long to follow the expansion (0 returns after the request is patched)")
+	resizeCmd.Flags().BoolVar(&resizeRestart, "restart-pod", false, "Restart the pod mounting the PVC when its filesystem did not grow (CSI drivers expanding offline only)")
+	addGuardFlags(resizeCmd)
+	resizeCmd.MarkFlagRequired("pvc")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseResizeTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		to, by  string
+		want    string
+		wantErr string
+	}{
+		{"absolute", "100Gi", "200Gi", "", "200Gi", ""},
+		{"relative size", "100Gi", "", "10Gi", "110Gi", ""},
+		{"percentage", "100Gi", "", "20%", "120Gi", ""},
+		{"percentage rounded up to GiB", "10Gi", "", "5%", "11Gi", ""},
+		{"percentage of a small volume rounded up to MiB", "100Mi", "", "10%", "110Mi", ""},
+		{"both", "100Gi", "200Gi", "10Gi", "", "not both"},
+		{"neither", "100Gi", "", "", "", "is required"},
+		{"invalid --to", "100Gi", "lots", "", "", "invalid --to"},
+		{"invalid --by", "100Gi", "", "lots", "", "invalid --by"},
+		{"zero percent", "100Gi", "", "0%", "", "invalid --by"},
+		{"negative percent", "100Gi", "", "-5%", "", "invalid --by"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResizeTarget(resource.MustParse(tt.current), tt.to, tt.by)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseResizeTarget() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseResizeTarget() error = %v", err)
+			}
+			if want := resource.MustParse(tt.want); got.Cmp(want) != 0 {
+				t.Errorf("ParseResizeTarget() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
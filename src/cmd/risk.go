@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AnnotationCriticality marks how critical the data on a PVC (or every PVC in a namespace) is
+const AnnotationCriticality = "spacio.io/criticality"
+
+// Risk levels
+const (
+	RiskHigh   = "HIGH"
+	RiskMedium = "MEDIUM"
+	RiskLow    = "LOW"
+)
+
+var (
+	protectedPatterns []string
+	riskHighOnly      bool
+)
+
+// isProtectedNamespace reports whether a namespace matches one of the protected globs
+func isProtectedNamespace(ns string, patterns []string) bool {
+	for _, p := range patterns {
+		if p != "" && globMatch(p, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssessRisk joins PVC → PV → StorageClass and decides how likely deleting the
+// claim is to lose data. snapshotsByPVC is nil when the snapshot API is unavailable.
+func AssessRisk(pvc corev1.PersistentVolumeClaim, nsAnnotations map[string]string, snapshotsByPVC map[string]int, patterns []string) RiskInfo {
+	info := RiskInfo{
+		Namespace: pvc.Namespace,
+		Name:      pvc.Name,
+		PVName:    pvc.Spec.VolumeName,
+		Snapshots: -1,
+		Protected: isProtectedNamespace(pvc.Namespace, patterns),
+	}
+	if pvc.Spec.StorageClassName != nil {
+		info.StorageClass = *pvc.Spec.StorageClassName
+	}
+
+	info.Criticality = strings.ToLower(strings.TrimSpace(pvc.Annotations[AnnotationCriticality]))
+	if info.Criticality == "" {
+		info.Criticality = strings.ToLower(strings.TrimSpace(nsAnnotations[AnnotationCriticality]))
+	}
+
+	// PV reclaim policy is authoritative; fall back to the StorageClass for unbound claims
+	if info.PVName != "" {
+		if pv, err := internal.GetPV(info.PVName); err == nil {
+			info.ReclaimPolicy = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			if info.StorageClass == "" {
+				info.StorageClass = pv.Spec.StorageClassName
+			}
+		}
+	}
+	if info.ReclaimPolicy == "" && info.StorageClass != "" {
+		if sc, err := internal.GetStorageClass(info.StorageClass); err == nil {
+			info.ReclaimPolicy = string(corev1.PersistentVolumeReclaimDelete)
+			if sc.ReclaimPolicy != nil {
+				info.ReclaimPolicy = string(*sc.ReclaimPolicy)
+			}
+		}
+	}
+
+	if snapshotsByPVC != nil {
+		info.Snapshots = snapshotsByPVC[pvc.Name]
+	}
+
+	deletes := info.ReclaimPolicy == string(corev1.PersistentVolumeReclaimDelete)
+	important := info.Protected || info.Criticality == "critical" || info.Criticality == "high"
+	hasSnapshot := info.Snapshots > 0
+
+	if deletes {
+		info.Reasons = append(info.Reasons, "reclaimPolicy Delete")
+	}
+	if info.Protected {
+		info.Reasons = append(info.Reasons, "protected namespace")
+	}
+	if info.Criticality != "" {
+		info.Reasons = append(info.Reasons, "criticality "+info.Criticality)
+	}
+	switch info.Snapshots {
+	case -1:
+		info.Reasons = append(info.Reasons, "snapshots unknown")
+	case 0:
+		info.Reasons = append(info.Reasons, "no ready snapshot")
+	}
+
+	switch {
+	case deletes && important && !hasSnapshot:
+		info.Risk = RiskHigh
+	case deletes && (important || !hasSnapshot):
+		info.Risk = RiskMedium
+	default:
+		info.Risk = RiskLow
+	}
+	return info
+}
+
+// RiskAssessor assesses the data-loss risk of deleting a PVC
+type RiskAssessor func(ns, name string) (RiskInfo, error)
+
+// NewRiskAssessor assesses PVCs against the live cluster. Only snapshots that
+// are ready to use count, since a pending or failed snapshot cannot restore
+// the data. Namespace annotations and snapshots are read once per namespace.
+func NewRiskAssessor(patterns []string) RiskAssessor {
+	nsAnnotations := map[string]map[string]string{}
+	readySnapshots := map[string]map[string]int{}
+	return func(ns, name string) (RiskInfo, error) {
+		pvc, err := internal.GetPVC(ns, name)
+		if err != nil {
+			return RiskInfo{}, fmt.Errorf("getting PVC %s/%s: %v", ns, name, err)
+		}
+		if _, ok := nsAnnotations[ns]; !ok {
+			nsAnnotations[ns] = map[string]string{}
+			if nsObj, err := internal.GetNamespace(ns); err == nil {
+				nsAnnotations[ns] = nsObj.Annotations
+			}
+		}
+		if _, ok := readySnapshots[ns]; !ok {
+			snapshots, err := internal.ListVolumeSnapshots(ns)
+			switch {
+			case err == nil:
+				readySnapshots[ns] = map[string]int{}
+				for _, snap := range snapshots {
+					if snap.ReadyToUse {
+						readySnapshots[ns][snap.SourcePVC]++
+					}
+				}
+			case err == internal.ErrSnapshotAPIUnavailable:
+				readySnapshots[ns] = nil
+			default:
+				return RiskInfo{}, fmt.Errorf("listing snapshots in %s: %v", ns, err)
+			}
+		}
+		return AssessRisk(*pvc, nsAnnotations[ns], readySnapshots[ns], patterns), nil
+	}
+}
+
+// DeleteBlocker returns why a claim with the given risk must not be deleted
+// automatically: it is in a protected namespace, critical, or its volume is
+// deleted with it and no ready snapshot keeps the data. Empty means it may be.
+func DeleteBlocker(risk RiskInfo) string {
+	switch {
+	case risk.Protected:
+		return "protected namespace"
+	case risk.Criticality == "critical" || risk.Criticality == "high":
+		return "criticality " + risk.Criticality
+	case risk.ReclaimPolicy == "":
+		return "reclaim policy unknown"
+	case risk.ReclaimPolicy == string(corev1.PersistentVolumeReclaimDelete) && risk.Snapshots <= 0:
+		return "reclaimPolicy Delete and no ready snapshot"
+	}
+	return ""
+}
+
+var riskCmd = &cobra.Command{
+	Use:   "risk",
+	Short: "Show which PVCs would lose their data if the claim is deleted",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespaces := []string{}
+		if allNamespaces {
+			nsList, err := internal.ListNamespaces()
+			if err != nil {
+				return err
+			}
+			namespaces = nsList
+		} else {
+			namespaces = []string{namespace}
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Namespace", "PVC", "PV", "Storage Class", "Reclaim Policy", "Snapshots", "Criticality", "Protected", "Risk", "Reasons"})
+
+		var high, medium int
+		for _, ns := range namespaces {
+			pvcs, err := internal.ListPVCs(ns)
+			if err != nil {
+				fmt.Printf("Error listing PVCs in namespace %s: %v\n", ns, err)
+				continue
+			}
+			if len(pvcs) == 0 {
+				continue
+			}
+
+			var nsAnnotations map[string]string
+			if nsObj, err := internal.GetNamespace(ns); err == nil {
+				nsAnnotations = nsObj.Annotations
+			}
+
+			var snapshotsByPVC map[string]int
+			snapshots, err := internal.ListVolumeSnapshots(ns)
+			if err == nil {
+				// only ready snapshots can be restored, as plan and apply count them
+				snapshotsByPVC = map[string]int{}
+				for _, snap := range snapshots {
+					if snap.ReadyToUse {
+						snapshotsByPVC[snap.SourcePVC]++
+					}
+				}
+			} else if err != internal.ErrSnapshotAPIUnavailable {
+				fmt.Printf("Error listing snapshots in namespace %s: %v\n", ns, err)
+			}
+
+			for _, pvc := range pvcs {
+				r := AssessRisk(pvc, nsAnnotations, snapshotsByPVC, protectedPatterns)
+				switch r.Risk {
+				case RiskHigh:
+					high++
+				case RiskMedium:
+					medium++
+				}
+				if riskHighOnly && r.Risk != RiskHigh {
+					continue
+				}
+
+				snaps := fmt.Sprintf("%d", r.Snapshots)
+				if r.Snapshots < 0 {
+					snaps = "?"
+				}
+				protected := "No"
+				if r.Protected {
+					protected = "Yes"
+				}
+				risk := r.Risk
+				if r.Risk == RiskHigh {
+					risk = "⚠️  " + risk
+				}
+				t.AppendRow(table.Row{r.Namespace, r.Name, displayOrDash(r.PVName), displayOrDash(r.StorageClass),
+					displayOrDash(r.ReclaimPolicy), snaps, displayOrDash(r.Criticality), protected, risk, strings.Join(r.Reasons, ", ")})
+			}
+		}
+
+		if t.Length() == 0 {
+			fmt.Println("No PVCs found")
+			return nil
+		}
+
+		t.Render()
+		fmt.Printf("\nHigh risk: %d, Medium risk: %d (protected namespaces: %s)\n", high, medium, strings.Join(protectedPatterns, ", "))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(riskCmd)
+	riskCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	riskCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Assess PVCs in all namespaces")
+	riskCmd.Flags().StringSliceVar(&protectedPatterns, "protected", []string{"prod*", "*-prod", "production"}, "Namespace globs holding data that must not be lost")
+	riskCmd.Flags().BoolVar(&riskHighOnly, "high-only", false, "Only show HIGH risk PVCs")
+}
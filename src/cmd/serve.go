@@ -0,0 +1,12 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run spacio as a long-running service",
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var snapshotRetention time.Duration
+
+// SnapshotAccounting is the result of joining VolumeSnapshots, their contents and PVCs
+type SnapshotAccounting struct {
+	ByPVC    map[string]SnapshotSummary // keyed by namespace/pvc
+	Orphaned []OrphanedSnapshotContent
+	TotalMB  int64
+	Stale    int
+}
+
+// CollectSnapshotAccounting aggregates VolumeSnapshots per PVC for the given
+// namespaces (all when nil), flags snapshots older than the retention, and
+// finds VolumeSnapshotContents whose VolumeSnapshot no longer exists.
+func CollectSnapshotAccounting(namespaces []string, retention time.Duration, now time.Time) (*SnapshotAccounting, error) {
+	snapshots, err := internal.ListVolumeSnapshots("")
+	if err != nil {
+		return nil, err
+	}
+	contents, err := internal.ListVolumeSnapshotContents()
+	if err != nil {
+		return nil, err
+	}
+
+	inScope := func(ns string) bool { return true }
+	if namespaces != nil {
+		scope := map[string]bool{}
+		for _, ns := range namespaces {
+			scope[ns] = true
+		}
+		inScope = func(ns string) bool { return scope[ns] }
+	}
+
+	contentsByName := map[string]internal.VolumeSnapshotContentInfo{}
+	for _, c := range contents {
+		contentsByName[c.Name] = c
+	}
+
+	acc := &SnapshotAccounting{ByPVC: map[string]SnapshotSummary{}}
+	snapshotUIDs := map[string]string{}
+	for _, snap := range snapshots {
+		snapshotUIDs[pvcKey(snap.Namespace, snap.Name)] = snap.UID
+		if !inScope(snap.Namespace) {
+			continue
+		}
+
+		key := pvcKey(snap.Namespace, snap.SourcePVC)
+		summary := acc.ByPVC[key]
+		summary.Namespace = snap.Namespace
+		summary.PVC = snap.SourcePVC
+		summary.Count++
+		if snap.ReadyToUse {
+			summary.Ready++
+		}
+
+		size := snap.RestoreSizeMB
+		if content, ok := contentsByName[snap.ContentName]; ok {
+			if size == 0 {
+				size = content.RestoreSizeMB
+			}
+			if content.DeletionPolicy != "" && !containsString(summary.DeletionPolicies, content.DeletionPolicy) {
+				summary.DeletionPolicies = append(summary.DeletionPolicies, content.DeletionPolicy)
+			}
+		}
+		summary.RestoreSizeMB += size
+		acc.TotalMB += size
+
+		age := now.Sub(snap.CreatedAt)
+		if days := int(age.Hours() / 24); days > summary.OldestAgeDays {
+			summary.OldestAgeDays = days
+		}
+		if retention > 0 && age > retention {
+			summary.Stale++
+			acc.Stale++
+		}
+		acc.ByPVC[key] = summary
+	}
+
+	// contents are cluster-scoped: only report orphans when auditing every namespace
+	if namespaces == nil {
+		for _, c := range contents {
+			uid, exists := snapshotUIDs[pvcKey(c.SnapshotNamespace, c.SnapshotName)]
+			if exists && (c.SnapshotUID == "" || c.SnapshotUID == uid) {
+				continue
+			}
+			acc.Orphaned = append(acc.Orphaned, OrphanedSnapshotContent{
+				Name:           c.Name,
+				Snapshot:       pvcKey(c.SnapshotNamespace, c.SnapshotName),
+				DeletionPolicy: c.DeletionPolicy,
+				Driver:         c.Driver,
+				SnapshotHandle: c.SnapshotHandle,
+				RestoreSizeMB:  c.RestoreSizeMB,
+				AgeDays:        int(now.Sub(c.CreatedAt).Hours() / 24),
+			})
+		}
+	}
+	return acc, nil
+}
+
+// Summaries returns the per-PVC summaries sorted by namespace/pvc
+func (acc *SnapshotAccounting) Summaries() []SnapshotSummary {
+	summaries := make([]SnapshotSummary, 0, len(acc.ByPVC))
+	for _, s := range acc.ByPVC {
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return pvcKey(summaries[i].Namespace, summaries[i].PVC) < pvcKey(summaries[j].Namespace, summaries[j].PVC)
+	})
+	return summaries
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SnapshotSummaryReport renders the snapshot section of the CLI report
+func SnapshotSummaryReport(clusterReport ClusterReport) string {
+	if len(clusterReport.SnapshotSummaries) == 0 && len(clusterReport.OrphanedSnapshots) == 0 {
+		return ""
+	}
+	report := strings.Builder{}
+
+	totalVal, totalUnit := util.FormatSizeMBorGB(clusterReport.TotalSnapshotMB)
+	report.WriteString("\n📸 VolumeSnapshot Storage\n")
+	report.WriteString("─────────────────────────────────────────────\n")
+	report.WriteString(fmt.Sprintf("PVCs with Snapshots      : %d\n", len(clusterReport.SnapshotSummaries)))
+	report.WriteString(fmt.Sprintf("Total Snapshot Size      : %.2f %s\n", totalVal, totalUnit))
+	report.WriteString(fmt.Sprintf("Snapshots past Retention : %d\n", clusterReport.StaleSnapshots))
+	report.WriteString(fmt.Sprintf("Orphaned Contents        : %d\n", len(clusterReport.OrphanedSnapshots)))
+	for _, c := range clusterReport.OrphanedSnapshots {
+		sizeVal, sizeUnit := util.FormatSizeMBorGB(c.RestoreSizeMB)
+		report.WriteString(fmt.Sprintf("  %s — snapshot %s missing, %.2f %s, deletionPolicy %s, %dd old\n",
+			c.Name, c.Snapshot, sizeVal, sizeUnit, displayOrDash(c.DeletionPolicy), c.AgeDays))
+	}
+
+	return report.String()
+}
+
+var snapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "Account VolumeSnapshot storage per PVC and find orphaned or stale snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var namespaces []string
+		if !allNamespaces {
+			namespaces = []string{namespace}
+		}
+
+		now := time.Now()
+		acc, err := CollectSnapshotAccounting(namespaces, snapshotRetention, now)
+		if err != nil {
+			return err
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Namespace", "PVC", "Snapshots", "Ready", "Restore Size", "Oldest", "Deletion Policy", "Past Retention"})
+		for _, s := range acc.Summaries() {
+			sizeVal, sizeUnit := util.FormatSizeMBorGB(s.RestoreSizeMB)
+			stale := fmt.Sprintf("%d", s.Stale)
+			if s.Stale > 0 {
+				stale = "⚠️  " + stale
+			}
+			t.AppendRow(table.Row{s.Namespace, s.PVC, s.Count, s.Ready, fmt.Sprintf("%.2f %s", sizeVal, sizeUnit),
+				fmt.Sprintf("%dd", s.OldestAgeDays), displayOrDash(strings.Join(s.DeletionPolicies, ", ")), stale})
+		}
+
+		if t.Length() == 0 {
+			fmt.Println("No VolumeSnapshots found")
+		} else {
+			t.Render()
+			totalVal, totalUnit := util.FormatSizeMBorGB(acc.TotalMB)
+			fmt.Printf("\nTotal snapshot size: %.2f %s, past retention (%s): %d\n", totalVal, totalUnit, snapshotRetention, acc.Stale)
+		}
+
+		if len(acc.Orphaned) > 0 {
+			o := table.NewWriter()
+			o.SetOutputMirror(os.Stdout)
+			o.SetTitle("Orphaned VolumeSnapshotContents")
+			o.AppendHeader(table.Row{"Content", "Missing Snapshot", "Driver", "Snapshot Handle", "Deletion Policy", "Restore Size", "Age"})
+			for _, c := range acc.Orphaned {
+				sizeVal, sizeUnit := util.FormatSizeMBorGB(c.RestoreSizeMB)
+				o.AppendRow(table.Row{c.Name, c.Snapshot, c.Driver, c.SnapshotHandle, displayOrDash(c.DeletionPolicy),
+					fmt.Sprintf("%.2f %s", sizeVal, sizeUnit), fmt.Sprintf("%dd", c.AgeDays)})
+			}
+			fmt.Println()
+			o.Render()
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotsCmd)
+	snapshotsCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	snapshotsCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Account snapshots in all namespaces (also finds orphaned contents)")
+	snapshotsCmd.Flags().DurationVar(&snapshotRetention, "retention", 30*24*time.Hour, "Flag snapshots older than this")
+}
@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	resizeTemplate        string
+	resizeIncludeRetained bool
+)
+
+// podSnapshot identifies a pod before the StatefulSet is recreated, to check afterwards it was left alone
+type podSnapshot struct {
+	UID      types.UID
+	Restarts int32
+}
+
+func podRestarts(pod corev1.Pod) int32 {
+	var restarts int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
+
+func snapshotPods(pods []corev1.Pod) map[string]podSnapshot {
+	snapshot := map[string]podSnapshot{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil {
+			snapshot[pod.Name] = podSnapshot{UID: pod.UID, Restarts: podRestarts(pod)}
+		}
+	}
+	return snapshot
+}
+
+// selectTemplates returns the volumeClaimTemplates to resize: the one named,
+// or the only one of the StatefulSet
+func selectTemplates(sts appsv1.StatefulSet, name string) ([]string, error) {
+	var names []string
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		if name == "" || tpl.Name == name {
+			names = append(names, tpl.Name)
+		}
+	}
+	switch {
+	case len(sts.Spec.VolumeClaimTemplates) == 0:
+		return nil, fmt.Errorf("StatefulSet %s/%s has no volumeClaimTemplates", sts.Namespace, sts.Name)
+	case len(names) == 0:
+		return nil, fmt.Errorf("StatefulSet %s/%s has no volumeClaimTemplate %q", sts.Namespace, sts.Name, name)
+	case len(names) > 1:
+		return nil, fmt.Errorf("StatefulSet %s/%s has %d volumeClaimTemplates, choose one with --template", sts.Namespace, sts.Name, len(names))
+	}
+	return names, nil
+}
+
+// statefulSetClaims returns the existing claims (<template>-<statefulset>-<ordinal>)
+// of the given templates: those of the current replicas, ordinals start to
+// start+replicas-1, and those retained outside that range by a scale-down or
+// a raised start ordinal, which the StatefulSet reuses at their old size when
+// the range covers them again. Claims not created yet pick up the new template.
+func statefulSetClaims(sts appsv1.StatefulSet, templates []string) (replicas, retained []corev1.PersistentVolumeClaim, err error) {
+	start := internal.StatefulSetOrdinalStart(sts)
+	end := start + int(replicasOf(sts))
+	for _, tpl := range templates {
+		for ordinal := start; ordinal < end; ordinal++ {
+			name := fmt.Sprintf("%s-%s-%d", tpl, sts.Name, ordinal)
+			pvc, err := internal.GetPVC(sts.Namespace, name)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("getting PVC %s/%s: %v", sts.Namespace, name, err)
+			}
+			replicas = append(replicas, *pvc)
+		}
+	}
+
+	pvcs, err := internal.ListPVCs(sts.Namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing PVCs in %s: %v", sts.Namespace, err)
+	}
+	for _, pvc := range pvcs {
+		for _, tpl := range templates {
+			prefix := tpl + "-" + sts.Name + "-"
+			if !strings.HasPrefix(pvc.Name, prefix) {
+				continue
+			}
+			ordinal, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix))
+			if err != nil || ordinal < 0 {
+				continue
+			}
+			if ordinal < start || ordinal >= end {
+				retained = append(retained, pvc)
+			}
+		}
+	}
+	sort.Slice(retained, func(i, j int) bool { return retained[i].Name < retained[j].Name })
+	return replicas, retained, nil
+}
+
+func replicasOf(sts appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
+
+// templateNeedsResize reports whether a selected volumeClaimTemplate requests less than the target
+func templateNeedsResize(sts appsv1.StatefulSet, templates []string, target resource.Quantity) bool {
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		for _, name := range templates {
+			if tpl.Name == name && tpl.Spec.Resources.Requests.Storage().Cmp(target) < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkTemplateTarget refuses a target below what a selected
+// volumeClaimTemplate already requests: new replicas would get smaller volumes
+func checkTemplateTarget(sts appsv1.StatefulSet, templates []string, target resource.Quantity) error {
+	for _, tpl := range sts.Spec.VolumeClaimTemplates {
+		for _, name := range templates {
+			if current := tpl.Spec.Resources.Requests.Storage(); tpl.Name == name && current.Cmp(target) > 0 {
+				return fmt.Errorf("volumeClaimTemplate %s of StatefulSet %s/%s requests %s, more than the target %s: volumes can only be expanded",
+					tpl.Name, sts.Namespace, sts.Name, current.String(), target.String())
+			}
+		}
+	}
+	return nil
+}
+
+// recreateStatefulSet replaces a StatefulSet with a copy whose selected
+// volumeClaimTemplates request the target size. The StatefulSet is deleted
+// with orphan propagation so its pods and PVCs keep running; the previous
+// manifest is written to backupFile first.
+func recreateStatefulSet(sts appsv1.StatefulSet, templates []string, target resource.Quantity, backupFile string, logf Logf) (*appsv1.StatefulSet, error) {
+	backup := sts.DeepCopy()
+	backup.ManagedFields = nil
+	backup.Status = appsv1.StatefulSetStatus{}
+	data, err := yaml.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(backupFile, data, 0644); err != nil {
+		return nil, fmt.Errorf("writing backup %s: %v", backupFile, err)
+	}
+	logf("previous manifest saved to %s", backupFile)
+
+	replacement := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        sts.Name,
+			Namespace:   sts.Namespace,
+			Labels:      sts.Labels,
+			Annotations: sts.Annotations,
+		},
+		Spec: *sts.Spec.DeepCopy(),
+	}
+	for i, tpl := range replacement.Spec.VolumeClaimTemplates {
+		for _, name := range templates {
+			if tpl.Name != name {
+				continue
+			}
+			if tpl.Spec.Resources.Requests == nil {
+				replacement.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			replacement.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests[corev1.ResourceStorage] = target
+		}
+	}
+
+	if err := internal.DeleteStatefulSetOrphan(sts); err != nil {
+		return nil, fmt.Errorf("deleting StatefulSet %s/%s: %v", sts.Namespace, sts.Name, err)
+	}
+	err = waitFor("StatefulSet "+sts.Name+" to be deleted", migrateStepTimeout, func() (bool, error) {
+		_, err := internal.GetStatefulSet(sts.Namespace, sts.Name)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v — restore it with: kubectl apply -f %s", err, backupFile)
+	}
+	logf("StatefulSet %s/%s deleted with --cascade=orphan", sts.Namespace, sts.Name)
+
+	created, err := internal.CreateStatefulSet(replacement)
+	if err != nil {
+		return nil, fmt.Errorf("recreating StatefulSet %s/%s: %v — its pods are orphaned, restore it with: kubectl apply -f %s", sts.Namespace, sts.Name, err, backupFile)
+	}
+	logf("StatefulSet %s/%s recreated with volumeClaimTemplates requesting %s", sts.Namespace, sts.Name, target.String())
+	return created, nil
+}
+
+// verifyPodsUntouched waits for the recreated StatefulSet to adopt the pods
+// and checks that none of them was replaced or restarted
+func verifyPodsUntouched(sts appsv1.StatefulSet, before map[string]podSnapshot, timeout time.Duration) error {
+	var pending []string
+	err := waitFor("pods to be adopted by StatefulSet "+sts.Name, timeout, func() (bool, error) {
+		pods, err := internal.ListStatefulSetPods(sts)
+		if err != nil {
+			return false, err
+		}
+		pending = nil
+		for _, pod := range pods {
+			if owner := metav1.GetControllerOf(&pod); owner == nil || owner.UID != sts.UID {
+				pending = append(pending, pod.Name)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%v (not adopted: %v)", err, pending)
+	}
+
+	pods, err := internal.ListStatefulSetPods(sts)
+	if err != nil {
+		return err
+	}
+	after := snapshotPods(pods)
+	var problems []string
+	for name, snap := range before {
+		now, ok := after[name]
+		switch {
+		case !ok:
+			problems = append(problems, name+" is gone")
+		case now.UID != snap.UID:
+			problems = append(problems, name+" was recreated")
+		case now.Restarts != snap.Restarts:
+			problems = append(problems, fmt.Sprintf("%s restarted (%d → %d)", name, snap.Restarts, now.Restarts))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("pods were disturbed: %v", problems)
+	}
+	return nil
+}
+
+// ResizeStatefulSet expands every existing replica claim of the selected
+// volumeClaimTemplates, then recreates the StatefulSet with the new template
+// size (the templates are immutable) and verifies its pods were untouched.
+// Claims already at the target are skipped, so a failed run can be repeated.
+// Claims retained outside the replica range are expanded too with
+// includeRetained, otherwise reported. The hooks of each claim run around
+// its expansion.
+func ResizeStatefulSet(ns, name, template string, target resource.Quantity, includeRetained bool, timeout time.Duration, hooks *Hooks, logf Logf) error {
+	sts, err := internal.GetStatefulSet(ns, name)
+	if err != nil {
+		return fmt.Errorf("getting StatefulSet %s/%s: %v", ns, name, err)
+	}
+	if owner := metav1.GetControllerOf(sts); owner != nil {
+		return fmt.Errorf("StatefulSet %s/%s is managed by %s/%s, change the volume size there", ns, name, owner.Kind, owner.Name)
+	}
+	templates, err := selectTemplates(*sts, template)
+	if err != nil {
+		return err
+	}
+	if err := checkTemplateTarget(*sts, templates, target); err != nil {
+		return err
+	}
+	claims, retained, err := statefulSetClaims(*sts, templates)
+	if err != nil {
+		return err
+	}
+	for _, pvc := range retained {
+		current := CurrentSize(pvc)
+		if current.Cmp(target) >= 0 {
+			continue
+		}
+		if includeRetained {
+			claims = append(claims, pvc)
+			continue
+		}
+		logf("⚠️  PVC %s is retained outside the replica range and stays %s; it is reused at that size if the StatefulSet scales back over it (--include-retained expands it too)", pvc.Name, current.String())
+	}
+
+	// validate every claim, and the quota for all of them together, before touching any
+	var toExpand []corev1.PersistentVolumeClaim
+	total := resource.Quantity{}
+	for _, pvc := range claims {
+		if current := CurrentSize(pvc); current.Cmp(target) >= 0 {
+			logf("PVC %s is already %s", pvc.Name, current.String())
+			continue
+		}
+		if err := ValidateExpansion(pvc, target); err != nil {
+			return fmt.Errorf("PVC %s: %v", pvc.Name, err)
+		}
+		delta := target.DeepCopy()
+		delta.Sub(*pvc.Spec.Resources.Requests.Storage())
+		total.Add(delta)
+		toExpand = append(toExpand, pvc)
+	}
+	if len(toExpand) > 1 {
+		if err := CheckStorageQuota(toExpand[0], total); err != nil {
+			return err
+		}
+	}
+
+	// once a claim was expanded a failure still counts as a change
+	expanded := 0
+	fail := func(err error) error {
+		if expanded > 0 {
+			return changeMade(err)
+		}
+		return err
+	}
+	for i, pvc := range toExpand {
+		logf("[%d/%d] expanding PVC %s", i+1, len(toExpand), pvc.Name)
+		claimLogf := func(format string, args ...interface{}) {
+			logf("  "+format, args...)
+		}
+		var result ResizeResult
+		current := CurrentSize(pvc)
+		err := hooks.Run(pvc, HookPayload{Action: ActionExpand, From: current.String(), To: target.String()}, claimLogf, func() error {
+			var err error
+			result, err = ExpandPVC(ns, pvc.Name, target, timeout, claimLogf)
+			return err
+		})
+		if err != nil {
+			if changeWasMade(err) {
+				expanded++
+			}
+			return fail(fmt.Errorf("PVC %s: %v — the StatefulSet was not changed, rerun once fixed", pvc.Name, err))
+		}
+		expanded++
+		if result.Phase == ResizePhaseFSPending {
+			logf("  PVC %s: %s", pvc.Name, result.Message)
+		}
+	}
+
+	if !templateNeedsResize(*sts, templates, target) {
+		logf("volumeClaimTemplates already request %s", target.String())
+		return nil
+	}
+	sts, err = internal.GetStatefulSet(ns, name)
+	if err != nil {
+		return fail(fmt.Errorf("getting StatefulSet %s/%s: %v", ns, name, err))
+	}
+	pods, err := internal.ListStatefulSetPods(*sts)
+	if err != nil {
+		return fail(err)
+	}
+	before := snapshotPods(pods)
+
+	os.MkdirAll("reports", 0755)
+	backupFile := filepath.Join("reports", fmt.Sprintf("statefulset-%s-%s-%s.yaml", ns, name, time.Now().Format("20060102-150405")))
+	created, err := recreateStatefulSet(*sts, templates, target, backupFile, logf)
+	if err != nil {
+		return fail(err)
+	}
+	if err := verifyPodsUntouched(*created, before, migrateStepTimeout); err != nil {
+		return changeMade(err)
+	}
+	logf("%d pod(s) adopted by the new StatefulSet, none restarted or replaced", len(before))
+	return nil
+}
+
+// statefulSetChange describes the expansion of a StatefulSet's replica claims for the guard
+func statefulSetChange(ns, name, template string, target resource.Quantity, includeRetained bool) (Change, error) {
+	change := Change{Namespace: ns, Name: name, Action: "expand-statefulset"}
+	sts, err := internal.GetStatefulSet(ns, name)
+	if err != nil {
+		return change, fmt.Errorf("getting StatefulSet %s/%s: %v", ns, name, err)
+	}
+	templates, err := selectTemplates(*sts, template)
+	if err != nil {
+		return change, err
+	}
+	claims, retained, err := statefulSetClaims(*sts, templates)
+	if err != nil {
+		return change, err
+	}
+	if includeRetained {
+		claims = append(claims, retained...)
+	}
+	for _, pvc := range claims {
+		if current := CurrentSize(pvc); current.Cmp(target) < 0 {
+			delta := target.DeepCopy()
+			delta.Sub(current)
+			change.Bytes += delta.Value()
+			change.Count++
+		}
+	}
+	return change, nil
+}
+
+var resizeStatefulSetCmd = &cobra.Command{
+	Use:     "statefulset <name>",
+	Aliases: []string{"sts"},
+	Short:   "Expand every replica PVC of a StatefulSet and recreate it with the larger volumeClaimTemplate",
+	Example: `  spacio resize statefulset postgres -n db --to 100Gi
+  spacio resize sts kafka -n streaming --template data --to 500Gi`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resizeTo == "" {
+			return fmt.Errorf("--to is required")
+		}
+		target, err := resource.ParseQuantity(resizeTo)
+		if err != nil {
+			return fmt.Errorf("invalid --to %q: %v", resizeTo, err)
+		}
+
+		guard, hooks, err := newCommandGuard()
+		if err != nil {
+			return err
+		}
+		change, err := statefulSetChange(namespace, args[0], resizeTemplate, target, resizeIncludeRetained)
+		if err != nil {
+			return err
+		}
+		logf := func(format string, args ...interface{}) {
+			fmt.Printf("  ↳ "+format+"\n", args...)
+		}
+
+		fmt.Printf("📏 Resizing StatefulSet %s/%s volumes to %s\n", namespace, args[0], target.String())
+		err = guard.Run(change, logf, func() error {
+			return ResizeStatefulSet(namespace, args[0], resizeTemplate, target, resizeIncludeRetained, resizeTimeout, hooks, logf)
+		})
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
+		fmt.Printf("✅ StatefulSet %s/%s now provisions %s volumes\n", namespace, args[0], target.String())
+		return nil
+	},
+}
+
+func init() {
+	resizeCmd.AddCommand(resizeStatefulSetCmd)
+	resizeStatefulSetCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	resizeStatefulSetCmd.Flags().StringVar(&resizeTo, "to", "", "Target size of every replica volume (e.g. 100Gi)")
+	resizeStatefulSetCmd.Flags().StringVar(&resizeTemplate, "template", "", "volumeClaimTemplate to resize (required when there are several)")
+	resizeStatefulSetCmd.Flags().BoolVar(&resizeIncludeRetained, "include-retained", false, "Also expand claims retained outside the replica range by a scale-down")
+	resizeStatefulSetCmd.Flags().DurationVar(&resizeTimeout, "timeout", 5*time.Minute, "How long to follow each PVC expansion")
+	addGuardFlags(resizeStatefulSetCmd)
+}
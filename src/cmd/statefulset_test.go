@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatefulSetTemplateTarget(t *testing.T) {
+	sts := appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"}}
+	for _, tpl := range []struct{ name, size string }{{"data", "50Gi"}, {"wal", "10Gi"}} {
+		claim := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: tpl.name}}
+		claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(tpl.size)}
+		sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, claim)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		target   string
+		resize   bool
+		refused  bool
+	}{
+		{"larger target", "data", "100Gi", true, false},
+		{"same size", "data", "50Gi", false, false},
+		{"smaller target", "data", "20Gi", false, true},
+		{"other template is larger", "wal", "20Gi", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := resource.MustParse(tt.target)
+			templates := []string{tt.template}
+			if got := templateNeedsResize(sts, templates, target); got != tt.resize {
+				t.Errorf("templateNeedsResize() = %v, want %v", got, tt.resize)
+			}
+			if err := checkTemplateTarget(sts, templates, target); (err != nil) != tt.refused {
+				t.Errorf("checkTemplateTarget() error = %v, want refused %v", err, tt.refused)
+			}
+		})
+	}
+}
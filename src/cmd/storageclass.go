@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	internal "pvc-audit/Internal"
+	"pvc-audit/util"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var storageClassSkipUsage bool
+
+// noStorageClass groups PVCs without a storage class (or with a class that no longer exists)
+const noStorageClass = "(none)"
+
+// CollectStorageClassInfos lists every StorageClass with aggregated PVC count,
+// allocated and (optionally) used space, and flags deprecated in-tree
+// provisioners, multiple defaults and classes whose PVCs cannot be expanded.
+func CollectStorageClassInfos(measureUsage bool) ([]StorageClassInfo, error) {
+	classes, err := internal.ListStorageClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := map[string]*StorageClassInfo{}
+	var defaults []string
+	for _, sc := range classes {
+		infos[sc.Name] = storageClassInfo(sc)
+		if infos[sc.Name].Default {
+			defaults = append(defaults, sc.Name)
+		}
+	}
+
+	pvcs, err := internal.ListPVCs("")
+	if err != nil {
+		return nil, err
+	}
+
+	// usage is measured by exec'ing du in running pods, as audit does
+	var clientset *kubernetes.Clientset
+	var config *rest.Config
+	if measureUsage {
+		clientset, config, err = internal.GetK8sClientWithConfig()
+		if err != nil {
+			fmt.Printf("⚠️  Skipping usage measurement: %v\n", err)
+			clientset = nil
+		}
+	}
+	for _, pvc := range pvcs {
+		className := ""
+		if pvc.Spec.StorageClassName != nil {
+			className = *pvc.Spec.StorageClassName
+		}
+		info, ok := infos[className]
+		if !ok {
+			if infos[noStorageClass] == nil {
+				infos[noStorageClass] = &StorageClassInfo{Name: noStorageClass}
+			}
+			info = infos[noStorageClass]
+		}
+
+		info.PVCCount++
+		if pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+		info.AllocatedMB += pvc.Status.Capacity.Storage().Value() / 1024 / 1024
+		if clientset != nil {
+			used, err := internal.GetUsedSizeInMB(clientset, config, pvc.Namespace, pvc.Name)
+			if err == nil {
+				info.UsedMB += used
+			}
+		}
+	}
+
+	result := make([]StorageClassInfo, 0, len(infos))
+	for _, info := range infos {
+		if info.Default && len(defaults) > 1 {
+			info.Findings = append(info.Findings, fmt.Sprintf("multiple default classes (%s)", strings.Join(defaults, ", ")))
+		}
+		if info.Name != noStorageClass && !info.AllowVolumeExpansion && info.PVCCount > 0 {
+			info.Findings = append(info.Findings, fmt.Sprintf("%d PVC(s) cannot be expanded", info.PVCCount))
+		}
+		if info.Name == noStorageClass && info.PVCCount > 0 {
+			info.Findings = append(info.Findings, "PVCs without an existing storage class")
+		}
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func storageClassInfo(sc storagev1.StorageClass) *StorageClassInfo {
+	info := &StorageClassInfo{
+		Name:                 sc.Name,
+		Provisioner:          sc.Provisioner,
+		AllowVolumeExpansion: internal.AllowsExpansion(sc),
+		VolumeBindingMode:    string(storagev1.VolumeBindingImmediate),
+		ReclaimPolicy:        string(corev1.PersistentVolumeReclaimDelete),
+		Default:              internal.IsDefaultStorageClass(sc),
+		Parameters:           sc.Parameters,
+	}
+	if sc.VolumeBindingMode != nil {
+		info.VolumeBindingMode = string(*sc.VolumeBindingMode)
+	}
+	if sc.ReclaimPolicy != nil {
+		info.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
+	if replacement, deprecated := internal.DeprecatedProvisioner(sc.Provisioner); deprecated {
+		finding := "deprecated in-tree provisioner"
+		if replacement != "" {
+			finding += ", migrate to " + replacement
+		}
+		info.Findings = append(info.Findings, finding)
+	}
+	return info
+}
+
+func formatParameters(params map[string]string) string {
+	if len(params) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params[k])
+	}
+	return strings.Join(pairs, "\n")
+}
+
+var storageClassesCmd = &cobra.Command{
+	Use:     "storageclasses",
+	Aliases: []string{"sc"},
+	Short:   "Audit StorageClasses: capabilities, configuration and PVC usage per class",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := CollectStorageClassInfos(!storageClassSkipUsage)
+		if err != nil {
+			return err
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Name", "Default", "Provisioner", "Expansion", "Binding Mode", "Reclaim Policy", "Parameters", "PVCs", "Allocated", "Used", "Findings"})
+
+		for _, sc := range infos {
+			def := ""
+			if sc.Default {
+				def = "✅"
+			}
+			expansion := "No"
+			if sc.AllowVolumeExpansion {
+				expansion = "Yes"
+			}
+			allocVal, allocUnit := util.FormatSizeMBorGB(sc.AllocatedMB)
+			used := "-"
+			if !storageClassSkipUsage {
+				usedVal, usedUnit := util.FormatSizeMBorGB(sc.UsedMB)
+				used = fmt.Sprintf("%.2f %s", usedVal, usedUnit)
+			}
+			findings := "-"
+			if len(sc.Findings) > 0 {
+				findings = "⚠️  " + strings.Join(sc.Findings, "\n⚠️  ")
+			}
+			t.AppendRow(table.Row{
+				sc.Name,
+				def,
+				displayOrDash(sc.Provisioner),
+				expansion,
+				displayOrDash(sc.VolumeBindingMode),
+				displayOrDash(sc.ReclaimPolicy),
+				formatParameters(sc.Parameters),
+				sc.PVCCount,
+				fmt.Sprintf("%.2f %s", allocVal, allocUnit),
+				used,
+				findings,
+			})
+		}
+
+		if t.Length() == 0 {
+			fmt.Println("No StorageClasses found")
+			return nil
+		}
+
+		t.Render()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(storageClassesCmd)
+	storageClassesCmd.Flags().BoolVar(&storageClassSkipUsage, "skip-usage", false, "Skip measuring used space (no exec into pods)")
+}
@@ -1,23 +1,138 @@
 package cmd
 
+import "time"
+
 // PVCInfo stores detailed information about a single PVC
 type PVCInfo struct {
-	Name          string  // PVC name
-	Namespace     string  // Namespace
-	AllocatedMB   int64   // Allocated storage in MB
-	Allocated     float64 // Allocated storage for display (MB or GB)
-	AllocatedUnit string  // "MB" or "GB"
-	UsedMB        int64   // Used storage in MB
-	Used          float64 // Used storage for display
-	UsedUnit      string  // "MB" or "GB"
-	WastedMB      int64   // Wasted storage in MB
-	Wasted        float64 // Wasted storage for display
-	WastedUnit    string  // "MB" or "GB"
-	WastagePct    int     // Percentage wasted
-	AttachedPod   string  // Pod using the PVC (empty if unattached)
-	Category      string
-	Attached      bool
-	UsedPct       int64
+	Name              string  // PVC name
+	Namespace         string  // Namespace
+	AllocatedMB       int64   // Allocated storage in MB
+	Allocated         float64 // Allocated storage for display (MB or GB)
+	AllocatedUnit     string  // "MB" or "GB"
+	UsedMB            int64   // Used storage in MB
+	Used              float64 // Used storage for display
+	UsedUnit          string  // "MB" or "GB"
+	WastedMB          int64   // Wasted storage in MB
+	Wasted            float64 // Wasted storage for display
+	WastedUnit        string  // "MB" or "GB"
+	WastagePct        int     // Percentage wasted
+	AttachedPod       string  // Pod using the PVC (a non-running pod if none is running, empty if unreferenced)
+	Category          string
+	Attached          bool      // true only when a running pod mounts the PVC
+	PodPhase          string    // Running, Pending, Terminated, Unknown or Unattached
+	ScaleDownLeftover string    // Set when left behind by a StatefulSet scale-down
+	OwnerStatefulSet  string    // StatefulSet the leftover PVC belongs to
+	StorageClass      string    // Storage class of the PVC
+	Provisioner       string    // Provisioner of the storage class
+	PeakUsedMB        int64     // Highest used storage seen in history (0 if unknown)
+	Forecasted        bool      // A growth trend was fitted on the usage history
+	GrowthMBPerDay    float64   // Growth rate from the usage history
+	DaysUntilFull     float64   // Projected days until full (-1 if not growing)
+	FullAt            time.Time // Projected full date (zero if not growing)
+	RecommendedMB     int64     // Recommended size (used/peak + headroom + growth, rounded)
+	ReclaimableMB     int64     // Allocated minus recommended, if positive
+	Confidence        string    // Recommendation confidence: high, medium or low
+	MonthlyCost       float64   // Monthly cost of the PVC (capacity and provisioned performance)
+	WastedCost        float64   // Monthly cost of the unused space
+	SavingsCost       float64   // Monthly cost of the space reclaimable by right-sizing
+	CostSource        string    // Where the cost comes from (empty if unpriced)
+	SnapshotCount     int       // VolumeSnapshots taken of the PVC
+	SnapshotMB        int64     // Restore size of those snapshots
+	ReferencedBy      []string  // Workloads referencing the PVC, e.g. "Deployment/api (0 replicas)"
+	UsedPct           int64
+
+	UID             string    // PVC UID, to detect a recreated claim
+	ResourceVersion string    // PVC resourceVersion at audit time, to detect drift
+	RequestedMB     int64     // spec.resources.requests.storage in MB
+	CreatedAt       time.Time // PVC creationTimestamp
+	BoundAt         time.Time // Bound PV creation time (zero if unbound)
+	LastPodActivity time.Time // Latest start/termination of a pod referencing the PVC
+	AgeDays         int       // Days since the PVC was created
+	IdleDays        int       // Days since the last activity (creation, bind or pod)
+
+	Suppressed         bool   // Finding suppressed by annotation or suppressions file
+	SuppressionExpired bool   // Matched a suppression that has expired (re-surfaced)
+	SuppressionReason  string // Why the finding is suppressed
+	SuppressionOwner   string // Who owns the suppression
+	SuppressionExpires string // Expiry date of the suppression (YYYY-MM-DD)
+}
+
+// PVInfo stores audit information about a single PersistentVolume
+type PVInfo struct {
+	Name          string // PV name
+	Phase         string // Available, Bound, Released, Failed, Pending
+	ReclaimPolicy string // Retain, Delete, Recycle
+	StorageClass  string
+	CapacityMB    int64
+	CSIDriver     string // CSI driver or in-tree plugin
+	VolumeHandle  string // Backend volume ID
+	ClaimRef      string // namespace/name of the bound claim (empty if none)
+	Finding       string // Why the PV is flagged (empty if healthy)
+	AgeDays       int
+}
+
+// PVCHealth describes a PVC that is not Bound (Pending or Lost)
+type PVCHealth struct {
+	Namespace    string
+	Name         string
+	Phase        string   // Pending or Lost
+	AgeDays      int      // Days since the PVC was created
+	Age          string   // Compact age for display
+	StorageClass string   // Requested storage class
+	RequestedMB  int64    // Requested storage in MB
+	Events       []string // Latest related events, newest first
+}
+
+// RiskInfo describes the data-loss risk of a single PVC if its claim is deleted
+type RiskInfo struct {
+	Namespace     string
+	Name          string
+	PVName        string
+	StorageClass  string
+	ReclaimPolicy string   // from the PV, falling back to the StorageClass
+	Snapshots     int      // VolumeSnapshots of the PVC (-1 if the snapshot API is unavailable)
+	Criticality   string   // spacio.io/criticality annotation (PVC, then namespace)
+	Protected     bool     // namespace matches a --protected pattern
+	Risk          string   // HIGH, MEDIUM or LOW
+	Reasons       []string // Why the risk level was assigned
+}
+
+// StorageClassInfo describes a StorageClass with the PVCs provisioned from it
+type StorageClassInfo struct {
+	Name                 string
+	Provisioner          string
+	AllowVolumeExpansion bool
+	VolumeBindingMode    string
+	ReclaimPolicy        string
+	Default              bool
+	Parameters           map[string]string
+	PVCCount             int
+	AllocatedMB          int64
+	UsedMB               int64
+	Findings             []string // Deprecated provisioner, multiple defaults, non-expandable PVCs
+}
+
+// SnapshotSummary aggregates the VolumeSnapshots taken of a single PVC
+type SnapshotSummary struct {
+	Namespace        string
+	PVC              string
+	Count            int      // Number of VolumeSnapshots
+	Ready            int      // Snapshots ready to use
+	RestoreSizeMB    int64    // Sum of restore sizes
+	OldestAgeDays    int      // Age of the oldest snapshot
+	Stale            int      // Snapshots older than the retention
+	DeletionPolicies []string // Distinct deletionPolicy values of the bound contents
+}
+
+// OrphanedSnapshotContent is a VolumeSnapshotContent whose VolumeSnapshot no longer exists
+type OrphanedSnapshotContent struct {
+	Name           string
+	Snapshot       string // namespace/name the content still points to
+	DeletionPolicy string
+	Driver         string
+	SnapshotHandle string
+	RestoreSizeMB  int64
+	AgeDays        int
 }
 
 // NamespaceReport aggregates PVCs for a namespace
@@ -28,19 +143,45 @@ type NamespaceReport struct {
 
 // ClusterReport aggregates all namespaces for a cluster
 type ClusterReport struct {
-	ClusterName        string            // Cluster name
-	GeneratedAt        string            // Timestamp
-	TotalNamespaces    int               // Count of namespaces audited
-	TotalPVCs          int               // Count of PVCs audited
-	PVCsWithWastage    int               // Number of PVCs with wastage > threshold
-	PVCsWithoutWastage int               // PVCs without wastage
-	TotalAllocatedGB   float64           // Total allocated storage in GB
-	TotalUsedGB        float64           // Total used storage in GB
-	TotalWastedGB      float64           // Total wasted storage in GB
-	TotalWastagePct    int64             // Total cluster wastage percentage
-	NamespaceReports   []NamespaceReport // Per-namespace details
-	HighWastagePVCs    []PVCInfo         // PVCs with wastage > 80%
-	UnattachedPVCs     []PVCInfo         // PVCs not attached to any pod
-	CleanupCandidates  []PVCInfo         // Suggested PVCs for cleanup
-	CSVFilePath        string            // Path to generated CSV file
+	ClusterName        string                    // Cluster name
+	GeneratedAt        string                    // Timestamp
+	TotalNamespaces    int                       // Count of namespaces audited
+	Namespaces         []string                  // Namespaces the audit covered (every namespace with -A)
+	AllNamespaces      bool                      // The audit covered the whole cluster (-A)
+	TotalPVCs          int                       // Count of PVCs audited
+	PVCsWithWastage    int                       // Number of PVCs with wastage > threshold
+	PVCsWithoutWastage int                       // PVCs without wastage
+	TotalAllocatedGB   float64                   // Total allocated storage in GB
+	TotalUsedGB        float64                   // Total used storage in GB
+	TotalWastedGB      float64                   // Total wasted storage in GB
+	TotalWastagePct    int64                     // Total cluster wastage percentage
+	NamespaceReports   []NamespaceReport         // Per-namespace details
+	HighWastagePVCs    []PVCInfo                 // PVCs with wastage > 80%
+	UnattachedPVCs     []PVCInfo                 // PVCs not attached to any pod
+	CleanupCandidates  []PVCInfo                 // Suggested PVCs for cleanup
+	FillingFastPVCs    []PVCInfo                 // PVCs projected to be full soon
+	DormantPVCs        []PVCInfo                 // Unattached PVCs still referenced by a workload
+	ScaleDownLeftovers []PVCInfo                 // StatefulSet PVCs outside the current ordinal range
+	OrphanedPVCs       []PVCInfo                 // Unattached PVCs referenced by nothing (incl. abandoned)
+	SuppressedPVCs     []PVCInfo                 // PVCs whose findings are suppressed
+	ExpiredSuppressed  []PVCInfo                 // PVCs re-surfaced because their suppression expired
+	UnhealthyPVCs      []PVCHealth               // PVCs that are not Bound, excluded from wastage numbers
+	PendingPVCs        int                       // Count of Pending PVCs
+	LostPVCs           int                       // Count of Lost PVCs
+	TotalReclaimableMB int64                     // Sum of reclaimable space from right-sizing (medium/high confidence)
+	LowConfidenceMB    int64                     // Reclaimable space of low-confidence recommendations, not in the totals
+	Currency           string                    // Currency of the cost figures (empty without pricing)
+	TotalMonthlyCost   float64                   // Monthly cost of all priced PVCs
+	TotalWastedCost    float64                   // Monthly cost of unused space
+	TotalSavingsCost   float64                   // Monthly cost reclaimable by right-sizing
+	UnpricedPVCs       int                       // PVCs without a matching price
+	SnapshotSummaries  []SnapshotSummary         // VolumeSnapshots per PVC
+	OrphanedSnapshots  []OrphanedSnapshotContent // Contents without a VolumeSnapshot (with -A)
+	TotalSnapshotMB    int64                     // Restore size of all snapshots of audited PVCs
+	StaleSnapshots     int                       // Snapshots older than --snapshot-retention
+	PVFindings         []PVInfo                  // Released, Available, Failed or claim-less PVs (with -A)
+	PVFindingsMB       int64                     // Capacity held by flagged PVs
+	CSVFilePath        string                    // Path to generated CSV file
+	JSONFilePath       string                    // Path to generated JSON report (usable as --baseline)
+	Baseline           *BaselineComparison       // Comparison against --baseline, if given
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations that opt a PVC (or every PVC in a namespace) out of wastage findings
+const (
+	AnnotationIgnore        = "spacio.io/ignore"
+	AnnotationExpectedUsage = "spacio.io/expected-usage"
+)
+
+// Suppression is a single entry of the suppressions file
+type Suppression struct {
+	Namespace string `json:"namespace"` // Namespace glob (empty matches all)
+	PVC       string `json:"pvc"`       // PVC name glob (empty matches all)
+	Reason    string `json:"reason"`    // Why the finding is accepted
+	Owner     string `json:"owner"`     // Who accepted it
+	Expires   string `json:"expires"`   // Expiry date (YYYY-MM-DD), empty never expires
+}
+
+// SuppressionsFile is the on-disk format of --suppressions (YAML or JSON)
+type SuppressionsFile struct {
+	Suppressions []Suppression `json:"suppressions"`
+}
+
+// SuppressionResult describes why a PVC is (or was) suppressed
+type SuppressionResult struct {
+	Suppressed bool
+	Expired    bool // matched a suppression whose expiry date has passed
+	Reason     string
+	Owner      string
+	Expires    string
+}
+
+// LoadSuppressions reads and validates a suppressions file
+func LoadSuppressions(file string) ([]Suppression, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading suppressions file: %v", err)
+	}
+
+	var sf SuppressionsFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("parsing suppressions file %s: %v", file, err)
+	}
+
+	for i, s := range sf.Suppressions {
+		if s.Reason == "" || s.Owner == "" {
+			return nil, fmt.Errorf("suppression #%d (%s/%s): reason and owner are required", i+1, s.Namespace, s.PVC)
+		}
+		if s.Expires != "" {
+			if _, err := time.Parse("2006-01-02", s.Expires); err != nil {
+				return nil, fmt.Errorf("suppression #%d (%s/%s): invalid expires %q, want YYYY-MM-DD", i+1, s.Namespace, s.PVC, s.Expires)
+			}
+		}
+	}
+	return sf.Suppressions, nil
+}
+
+// matches reports whether the suppression applies to the given PVC
+func (s Suppression) matches(ns, pvcName string) bool {
+	return globMatch(s.Namespace, ns) && globMatch(s.PVC, pvcName)
+}
+
+// expired reports whether the suppression is past its expiry date.
+// A suppression is valid through the whole expiry day.
+func (s Suppression) expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, _ := time.ParseInLocation("2006-01-02", s.Expires, now.Location())
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+func globMatch(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// ResolveSuppression decides whether a PVC finding should be suppressed, checking
+// PVC annotations first, then namespace annotations, then the suppressions file.
+func ResolveSuppression(pvc corev1.PersistentVolumeClaim, nsAnnotations map[string]string, allocatedMB int64, rules []Suppression, now time.Time) SuppressionResult {
+	if res, ok := annotationSuppression(pvc.Annotations, allocatedMB, "PVC"); ok {
+		return res
+	}
+	if res, ok := annotationSuppression(nsAnnotations, allocatedMB, "namespace"); ok {
+		return res
+	}
+
+	var expired *Suppression
+	for i, s := range rules {
+		if !s.matches(pvc.Namespace, pvc.Name) {
+			continue
+		}
+		if s.expired(now) {
+			if expired == nil {
+				expired = &rules[i]
+			}
+			continue
+		}
+		return SuppressionResult{Suppressed: true, Reason: s.Reason, Owner: s.Owner, Expires: s.Expires}
+	}
+
+	if expired != nil {
+		return SuppressionResult{Expired: true, Reason: expired.Reason, Owner: expired.Owner, Expires: expired.Expires}
+	}
+	return SuppressionResult{}
+}
+
+// annotationSuppression checks spacio.io/ignore and spacio.io/expected-usage.
+// An expected usage suppresses the finding as long as the allocation does not
+// exceed the declared size.
+func annotationSuppression(annotations map[string]string, allocatedMB int64, source string) (SuppressionResult, bool) {
+	if v, ok := annotations[AnnotationIgnore]; ok && strings.EqualFold(strings.TrimSpace(v), "true") {
+		return SuppressionResult{Suppressed: true, Reason: fmt.Sprintf("%s annotated %s", source, AnnotationIgnore), Owner: source + " annotation"}, true
+	}
+
+	if v, ok := annotations[AnnotationExpectedUsage]; ok {
+		q, err := resource.ParseQuantity(strings.TrimSpace(v))
+		if err != nil {
+			fmt.Printf("⚠️  Ignoring invalid %s annotation %q on %s: %v\n", AnnotationExpectedUsage, v, source, err)
+			return SuppressionResult{}, false
+		}
+		expectedMB := q.Value() / 1024 / 1024
+		if allocatedMB <= expectedMB {
+			return SuppressionResult{Suppressed: true, Reason: fmt.Sprintf("expected usage %s (%s annotation)", q.String(), source), Owner: source + " annotation"}, true
+		}
+	}
+	return SuppressionResult{}, false
+}
+
+// SuppressionSummary renders the suppressed and re-surfaced findings sections of the CLI report
+func SuppressionSummary(clusterReport ClusterReport) string {
+	report := strings.Builder{}
+
+	if len(clusterReport.SuppressedPVCs) > 0 {
+		report.WriteString("\n🔕 Suppressed Findings\n")
+		report.WriteString("─────────────────────────────────────────────\n")
+		for _, pvc := range clusterReport.SuppressedPVCs {
+			expires := pvc.SuppressionExpires
+			if expires == "" {
+				expires = "never"
+			}
+			report.WriteString(fmt.Sprintf("  %s/%s (%s, %d%% wasted) — %s [owner: %s, expires: %s]\n",
+				pvc.Namespace, pvc.Name, pvc.Category, pvc.WastagePct, pvc.SuppressionReason, pvc.SuppressionOwner, expires))
+		}
+	}
+
+	if len(clusterReport.ExpiredSuppressed) > 0 {
+		report.WriteString("\n⏰ Expired Suppressions (re-surfaced)\n")
+		report.WriteString("─────────────────────────────────────────────\n")
+		for _, pvc := range clusterReport.ExpiredSuppressed {
+			report.WriteString(fmt.Sprintf("  %s/%s (%s, %d%% wasted) — expired %s: %s [owner: %s]\n",
+				pvc.Namespace, pvc.Name, pvc.Category, pvc.WastagePct, pvc.SuppressionExpires, pvc.SuppressionReason, pvc.SuppressionOwner))
+		}
+	}
+
+	return report.String()
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	internal "pvc-audit/Internal"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	webhookListen    string
+	webhookPath      string
+	webhookTokenFile string
+	webhookInsecure  bool
+	webhookDryRun    bool
+	webhookTimeout   time.Duration
+)
+
+// Alert labels identifying the PVC, as set by the kubelet volume stats metrics
+const (
+	alertLabelNamespace = "namespace"
+	alertLabelPVC       = "persistentvolumeclaim"
+)
+
+// Outcomes of an alert received by the webhook
+const (
+	WebhookExpanded = "expanded"
+	WebhookDryRun   = "dry-run" // would be expanded
+	WebhookSkipped  = "skipped" // resolved, not a PVC, not enabled, cooling down or at the maximum size
+	WebhookRefused  = "refused" // outside the maintenance window or change budget
+	WebhookBusy     = "busy"    // the concurrency cap is reached, answered 503 so that Alertmanager retries
+	WebhookFailed   = "failed"
+)
+
+// insecureWebhookListen is where a webhook without a token listens by default
+const insecureWebhookListen = "127.0.0.1:8080"
+
+// maxWebhookBody bounds the size of an Alertmanager notification
+const maxWebhookBody = 1 << 20
+
+// AlertmanagerAlert is a single alert of an Alertmanager webhook notification
+type AlertmanagerAlert struct {
+	Status      string            `json:"status"` // firing or resolved
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"startsAt"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+}
+
+// AlertmanagerPayload is the body of an Alertmanager webhook notification (version 4)
+type AlertmanagerPayload struct {
+	Version  string              `json:"version"`
+	GroupKey string              `json:"groupKey"`
+	Status   string              `json:"status"`
+	Receiver string              `json:"receiver"`
+	Alerts   []AlertmanagerAlert `json:"alerts"`
+}
+
+// WebhookOutcome is what the webhook did with one PVC of a notification
+type WebhookOutcome struct {
+	Alert      string `json:"alert,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	PVC        string `json:"pvc,omitempty"`
+	Status     string `json:"status"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	Phase      string `json:"phase,omitempty"`
+	Filesystem string `json:"filesystem,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// WebhookResponse is the JSON body answered to Alertmanager
+type WebhookResponse struct {
+	Outcomes []WebhookOutcome `json:"outcomes"`
+}
+
+// WebhookHandler expands the PVCs of firing Alertmanager alerts, following
+// the auto-expand policy, the guard and the hooks like the autoscale controller
+type WebhookHandler struct {
+	policy  AutoExpandPolicy
+	guard   *Guard
+	hooks   *Hooks
+	token   string // bearer token required when set
+	dryRun  bool
+	timeout time.Duration
+	logf    Logf
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+	slots    chan struct{} // caps the expansions running at once, like the autoscale controller
+}
+
+// NewWebhookHandler builds the Alertmanager receiver
+func NewWebhookHandler(policy Policy, guard *Guard, token string, dryRun bool, timeout time.Duration, logf Logf) *WebhookHandler {
+	return &WebhookHandler{
+		policy:   policy.AutoExpand,
+		guard:    guard,
+		hooks:    NewHooks(policy.Hooks),
+		token:    token,
+		dryRun:   dryRun,
+		timeout:  timeout,
+		logf:     logf,
+		inFlight: map[string]bool{},
+		slots:    make(chan struct{}, policy.AutoExpand.ConcurrencyOrDefault()),
+	}
+}
+
+// ServeHTTP handles a notification: every PVC of a firing alert is handled
+// once, and the outcomes are answered as JSON. Failed expansions answer 500
+// so that Alertmanager retries. A retry of an expansion that failed before
+// its request was made expands again; once the request was made, the
+// cooldown keeps a retry from expanding the volume twice.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+	}
+	var payload AlertmanagerPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBody)).Decode(&payload); err != nil {
+		http.Error(w, "invalid Alertmanager payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := WebhookResponse{Outcomes: []WebhookOutcome{}}
+	status := http.StatusOK
+	seen := map[string]bool{}
+	for _, alert := range payload.Alerts {
+		ns, name := alert.Labels[alertLabelNamespace], alert.Labels[alertLabelPVC]
+		key := ns + "/" + name
+		if ns != "" && name != "" && seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		outcome := h.handleAlert(alert)
+		h.logf("%s %s/%s (%s): %s", outcome.Status, outcome.Namespace, outcome.PVC, outcome.Alert, outcome.Message)
+		switch {
+		case outcome.Status == WebhookFailed:
+			status = http.StatusInternalServerError
+		case outcome.Status == WebhookBusy && status == http.StatusOK:
+			status = http.StatusServiceUnavailable
+		}
+		response.Outcomes = append(response.Outcomes, outcome)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAlert maps an alert to its PVC and expands it when the policy allows
+func (h *WebhookHandler) handleAlert(alert AlertmanagerAlert) WebhookOutcome {
+	outcome := WebhookOutcome{
+		Alert:     alert.Labels["alertname"],
+		Namespace: alert.Labels[alertLabelNamespace],
+		PVC:       alert.Labels[alertLabelPVC],
+		Status:    WebhookSkipped,
+	}
+	if alert.Status != "firing" {
+		outcome.Message = "alert is " + alert.Status
+		return outcome
+	}
+	if outcome.Namespace == "" || outcome.PVC == "" {
+		outcome.Message = fmt.Sprintf("alert has no %s and %s labels", alertLabelNamespace, alertLabelPVC)
+		return outcome
+	}
+
+	key := outcome.Namespace + "/" + outcome.PVC
+	h.mu.Lock()
+	busy := h.inFlight[key]
+	h.inFlight[key] = true
+	h.mu.Unlock()
+	if busy {
+		outcome.Message = "an expansion of the PVC is already running"
+		return outcome
+	}
+	defer func() {
+		h.mu.Lock()
+		delete(h.inFlight, key)
+		h.mu.Unlock()
+	}()
+
+	pvc, err := internal.GetPVC(outcome.Namespace, outcome.PVC)
+	if apierrors.IsNotFound(err) {
+		outcome.Message = "PVC not found"
+		return outcome
+	}
+	if err != nil {
+		outcome.Status = WebhookFailed
+		outcome.Message = fmt.Sprintf("getting PVC: %v", err)
+		return outcome
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		outcome.Message = fmt.Sprintf("PVC is %s", pvc.Status.Phase)
+		return outcome
+	}
+	settings, err := h.policy.ExpandSettingsFor(*pvc)
+	if err != nil {
+		h.logf("⚠️  %s: %v", key, err)
+	}
+	if !settings.Enabled {
+		outcome.Message = "auto-expansion is not enabled for the PVC (policy or " + AnnotationAutoExpand + " annotation)"
+		return outcome
+	}
+
+	d := decideGrowth(*pvc, settings, time.Now(), ExpandDecision{Current: CurrentSize(*pvc)}, fmt.Sprintf("alert %s firing", outcome.Alert))
+	outcome.From = d.Current.String()
+	outcome.Message = d.Reason
+	if !d.Expand {
+		if d.AtMax {
+			internal.RecordEvent(internal.PVCReference(*pvc), corev1.EventTypeWarning, EventAutoExpandAtMax, d.Reason)
+		}
+		return outcome
+	}
+	outcome.To = d.Target.String()
+	if h.dryRun {
+		outcome.Status = WebhookDryRun
+		return outcome
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		outcome.Status = WebhookBusy
+		outcome.Message = fmt.Sprintf("%s, deferred: %d expansions already running", d.Reason, cap(h.slots))
+		return outcome
+	}
+	delta := d.Target.DeepCopy()
+	delta.Sub(d.Current)
+	admission, err := h.guard.Admit(Change{Namespace: pvc.Namespace, Name: pvc.Name, Action: ActionExpand, Bytes: delta.Value()}, time.Now())
+	if err != nil {
+		outcome.Status = WebhookRefused
+		outcome.Message = err.Error()
+		return outcome
+	}
+
+	result, err := autoExpand(*pvc, d, admission, h.guard, h.hooks, h.timeout, func(format string, args ...interface{}) {
+		h.logf("  ↳ %s: "+format, append([]interface{}{key}, args...)...)
+	})
+	outcome.Phase = result.Phase
+	outcome.Filesystem = result.Filesystem.Status
+	if err != nil {
+		outcome.Status = WebhookFailed
+		outcome.Message = err.Error()
+		return outcome
+	}
+	outcome.Status = WebhookExpanded
+	outcome.Message = fmt.Sprintf("%s: expanded %s → %s", d.Reason, outcome.From, outcome.To)
+	if result.Filesystem.Status == FilesystemPending {
+		outcome.Message += "; " + result.Filesystem.Message
+	}
+	return outcome
+}
+
+var serveWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Serve an Alertmanager webhook receiver that expands the PVCs of firing alerts",
+	Example: `  spacio serve webhook --policy policy.yaml
+  spacio serve webhook --listen :9095 --path /alerts --token-file /etc/spacio/token --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := LoadPolicy(policyFile)
+		if err != nil {
+			return err
+		}
+		guard, err := NewGuard(policy.Governance, false, "")
+		if err != nil {
+			return err
+		}
+		if webhookTokenFile == "" && !webhookInsecure {
+			return fmt.Errorf("--token-file is required; pass --insecure to accept unauthenticated notifications on %s", insecureWebhookListen)
+		}
+		listen := webhookListen
+		if listen == "" {
+			listen = ":8080"
+			if webhookTokenFile == "" {
+				listen = insecureWebhookListen
+			}
+		}
+		var token string
+		if webhookTokenFile != "" {
+			data, err := os.ReadFile(webhookTokenFile)
+			if err != nil {
+				return fmt.Errorf("reading token file: %v", err)
+			}
+			token = strings.TrimSpace(string(data))
+			if token == "" {
+				return fmt.Errorf("token file %s is empty", webhookTokenFile)
+			}
+		}
+		logf := func(format string, args ...interface{}) {
+			fmt.Printf("%s "+format+"\n", append([]interface{}{time.Now().Format(time.RFC3339)}, args...)...)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(webhookPath, NewWebhookHandler(policy, guard, token, webhookDryRun, webhookTimeout, logf))
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+		server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			// let running expansions answer before exiting
+			shutdown, cancel := context.WithTimeout(context.Background(), webhookTimeout+time.Minute)
+			defer cancel()
+			server.Shutdown(shutdown)
+		}()
+
+		if token == "" {
+			logf("⚠️  no --token-file: anyone reaching %s can trigger expansions", listen)
+		}
+		logf("🪝 webhook listening on %s%s, %d concurrent expansion(s)", listen, webhookPath, policy.AutoExpand.ConcurrencyOrDefault())
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.AddCommand(serveWebhookCmd)
+	serveWebhookCmd.Flags().StringVar(&webhookListen, "listen", "", "Address to listen on (default :8080, or 127.0.0.1:8080 with --insecure)")
+	serveWebhookCmd.Flags().StringVar(&webhookPath, "path", "/webhook", "Path of the Alertmanager webhook")
+	serveWebhookCmd.Flags().StringVar(&webhookTokenFile, "token-file", "", "File with a bearer token Alertmanager must send (http_config.authorization); required unless --insecure")
+	serveWebhookCmd.Flags().BoolVar(&webhookInsecure, "insecure", false, "Accept notifications without a bearer token (listens on 127.0.0.1 unless --listen is set)")
+	serveWebhookCmd.Flags().StringVar(&policyFile, "policy", "", "Policy file (YAML or JSON) with auto-expand rules, maintenance windows, change budgets and hooks")
+	serveWebhookCmd.Flags().BoolVar(&webhookDryRun, "dry-run", false, "Only answer the expansions that would be made")
+	serveWebhookCmd.Flags().DurationVar(&webhookTimeout, "timeout", 2*time.Minute, "How long to follow each expansion before answering")
+	serveWebhookCmd.Flags().BoolVar(&resizeRestart, "restart-pod", false, "Restart pods whose filesystem did not grow after an expansion (CSI drivers expanding offline only)")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandlerRejects(t *testing.T) {
+	h := NewWebhookHandler(Policy{}, &Guard{}, "s3cret", true, time.Minute, func(string, ...interface{}) {})
+	body := `{"version":"4","status":"resolved","alerts":[{"status":"resolved","labels":{"namespace":"db","persistentvolumeclaim":"data"}}]}`
+
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		body   string
+		status int
+	}{
+		{"GET", http.MethodGet, "Bearer s3cret", body, http.StatusMethodNotAllowed},
+		{"no token", http.MethodPost, "", body, http.StatusUnauthorized},
+		{"wrong token", http.MethodPost, "Bearer guess", body, http.StatusUnauthorized},
+		{"invalid payload", http.MethodPost, "Bearer s3cret", "{", http.StatusBadRequest},
+		{"resolved alert", http.MethodPost, "Bearer s3cret", body, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
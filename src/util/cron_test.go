@@ -0,0 +1,87 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleMatches(t *testing.T) {
+	// 2024-06-03 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"0 22 * * 1-5", at(3, 22, 0), true},
+		{"0 22 * * 1-5", at(8, 22, 0), false}, // Saturday
+		{"0 22 * * 1-5", at(3, 22, 1), false},
+		{"*/15 * * * *", at(3, 10, 45), true},
+		{"*/15 * * * *", at(3, 10, 46), false},
+		{"5/20 * * * *", at(3, 10, 45), true},
+		{"5/20 * * * *", at(3, 10, 5), true},
+		{"5/20 * * * *", at(3, 10, 20), false},
+		{"0 9-17/4 * * *", at(3, 13, 0), true},
+		{"0 9-17/4 * * *", at(3, 15, 0), false},
+		{"0 0 * * 7", at(9, 0, 0), true}, // Sunday as 7
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		{"0 0 1,15 * *", at(14, 0, 0), false},
+		// both day fields restricted: either matches
+		{"0 0 1 * 1", at(3, 0, 0), true},
+		{"0 0 1 * 1", at(1, 0, 0), true},
+		{"0 0 1 * 1", at(4, 0, 0), false},
+		// a stepped * leaves the day unrestricted, so only the other field decides
+		{"0 0 */2 * 1", at(3, 0, 0), true},
+		{"0 0 */2 * 1", at(5, 0, 0), false},
+		{"0 0 1 * */2", at(1, 0, 0), true},
+		{"0 0 1 * */2", at(4, 0, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+" "+tt.at.Format(time.RFC3339), func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Matches(tt.at); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"0 22 * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err == nil {
+				t.Errorf("ParseCron(%q) = nil error", expr)
+			}
+		})
+	}
+}
+
+func TestCronScheduleLastStart(t *testing.T) {
+	s, err := ParseCron("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, time.June, 3, 4, 10, 42, 0, time.UTC)
+	got, ok := s.LastStart(now, 3*time.Hour)
+	if want := time.Date(2024, time.June, 3, 2, 30, 0, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("LastStart() = %v, %v, want %v", got, ok, want)
+	}
+	if _, ok := s.LastStart(now, time.Hour); ok {
+		t.Error("LastStart() within 1h found a start")
+	}
+}